@@ -8,9 +8,17 @@ import (
 	"github.com/rikotsev/easy-release/internal/strategy"
 )
 
-const configFileName = ".easy-release.json"
+const (
+	configFileName   = ".easy-release.json"
+	changelogCommand = "changelog"
+)
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == changelogCommand {
+		changelog(os.Args[2:])
+		return
+	}
+
 	args, err := strategy.LoadEasyReleaseArgs()
 	if err != nil {
 		slog.Error("failed to load args", "err", err)
@@ -33,6 +41,7 @@ func main() {
 	}
 	if result == strategy.Done {
 		slog.Info("a release was performed. no need to perform a new release on this run. exiting")
+		printDryRunPlan(args, appCtx)
 		os.Exit(0)
 	}
 
@@ -43,4 +52,42 @@ func main() {
 		os.Exit(1)
 	}
 
+	printDryRunPlan(args, appCtx)
+}
+
+func printDryRunPlan(args *strategy.EasyReleaseArgs, appCtx *strategy.EasyReleaseContext) {
+	if appCtx.Recorder == nil {
+		return
+	}
+
+	plan := appCtx.Recorder.Plan()
+	write := plan.WriteText
+	if args.DryRunOutput == strategy.DryRunJson {
+		write = plan.WriteJson
+	}
+
+	if err := write(os.Stdout); err != nil {
+		slog.Error("failed to print the dry run plan", "err", err)
+		os.Exit(1)
+	}
+}
+
+// changelog runs `easy-release changelog -rebuild`, which works with the local repository only.
+func changelog(arguments []string) {
+	args, err := strategy.LoadChangelogArgs(arguments)
+	if err != nil {
+		slog.Error("failed to load args", "err", err)
+		os.Exit(1)
+	}
+
+	appCtx, err := strategy.CreateChangelogContext(args)
+	if err != nil {
+		slog.Error("failed to create application context", "err", err)
+		os.Exit(1)
+	}
+
+	if _, err := strategy.RebuildChangelog(args, appCtx).Execute(context.Background()); err != nil {
+		slog.Error("failed to rebuild the changelog", "err", err)
+		os.Exit(1)
+	}
 }
@@ -60,13 +60,7 @@ func initServices(args *strategy.EasyReleaseArgs) (*commits.CommitLinter, vcs.Ap
 		return nil, nil, fmt.Errorf("failed to initialize linter: %w", err)
 	}
 
-	api, err := vcs.NewAzureDevops(cfg, vcs.ApiOpts{
-		Token:   args.Token,
-		Org:     args.Org,
-		Project: args.Project,
-		Repo:    args.Repo,
-		Branch:  args.Branch,
-	})
+	api, err := strategy.CreateApi(cfg, args)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to initialize api: %w", err)
 	}
@@ -3,6 +3,10 @@ package changelog
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
 	"text/template"
 	"time"
 
@@ -14,89 +18,271 @@ type ChangelogBuilder struct {
 	cfg                 *config.Config
 	commitTypeToSection map[string]*config.ChangelogSection
 	tpl                 *template.Template
+	pullRequestTpl      *template.Template
+	compareUrl          string
+	commitUrl           string
 }
 
+// SectionItem is a single commit in a section of the changelog.
 type SectionItem struct {
 	Title       string
 	HasLink     bool
-	LinkPreview string
-	Link        string
+	LinkPreview string // the first link as written in the commit - `JIRA-123`
+	Link        string // the URL of the first link
+	Links       []commits.IssueLink
+	Type        string
+	Scope       string
+	ScopeName   string // the scope as configured in scopeNames - the scope itself by default
+	Sha         string
+	ShortSha    string // the first 7 characters of the sha
+	CommitUrl   string // empty when there is no commit URL pattern
+	Author      string
+	Breaking    bool
+	Body        string
 }
 
 type TemplateSection struct {
-	Title string
+	Title  string
+	Hidden bool // hidden sections count for the version but are left out of the changelog and PR description
+	Items  []SectionItem
+	Groups []ScopeGroup // the items grouped by scope name - the items without a scope come first
+}
+
+type ScopeGroup struct {
+	Name  string // empty for the items without a scope
 	Items []SectionItem
 }
 
+// Changelog is the data the changelog and pull request templates are executed with.
 type Changelog struct {
-	Version  string
-	Date     string
-	Sections []TemplateSection
+	Version         string
+	PreviousVersion string // empty for the first release
+	CompareUrl      string // the diff between the previous and the new tag - empty for the first release or without a pattern
+	Date            string // formatted as 2006-01-02
+	Sections        []TemplateSection
+	Contributors    []string // the distinct commit authors in order of appearance
+	ScopeStyle      string   // NONE, PREFIX or GROUP
 }
 
-const tplContent = `
-## {{.Version}} ({{.Date}})
+const tplContent = `{{ define "item" }}{{ range .Links }}[{{ .Text }}]({{ .Url }}) {{ end }}{{ .Title }}{{ if .CommitUrl }} ([{{ .ShortSha }}]({{ .CommitUrl }})){{ end }}{{ end }}
+## {{ if .CompareUrl }}[{{.Version}}]({{.CompareUrl}}){{ else }}{{.Version}}{{ end }} ({{.Date}})
 {{ range $is, $section := .Sections }}
-### {{ $section.Title }}{{ range $ii, $item := $section.Items  }}
-* {{ if $item.HasLink }}[{{ $item.LinkPreview }}]({{ $item.Link }}) {{ end }}{{ $item.Title }}{{ end  }}
+### {{ $section.Title }}{{ if eq $.ScopeStyle "GROUP" }}{{ range $group := $section.Groups }}{{ if $group.Name }}
+
+#### {{ $group.Name }}{{ end }}{{ range $ii, $item := $group.Items }}
+* {{ template "item" $item }}{{ end }}{{ end }}{{ else }}{{ range $ii, $item := $section.Items  }}
+* {{ if and (eq $.ScopeStyle "PREFIX") $item.ScopeName }}**{{ $item.ScopeName }}:** {{ end }}{{ template "item" $item }}{{ end  }}{{ end }}
 {{ end  }}`
 
-func NewBuilder(cfg *config.Config, commitTypeToSection map[string]*config.ChangelogSection) (*ChangelogBuilder, error) {
+const shortShaLength = 7
 
-	tpl, err := template.New("changelog").Parse(tplContent)
+func NewBuilder(cfg *config.Config, commitTypeToSection map[string]*config.ChangelogSection) (*ChangelogBuilder, error) {
+	tpl, err := loadTemplate("changelog", cfg.ChangelogTemplate, tplContent)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse template: %w", err)
+		return nil, err
+	}
+
+	pullRequestTpl := tpl
+	if cfg.PullRequestTemplate != "" {
+		pullRequestTpl, err = loadTemplate("pull request", cfg.PullRequestTemplate, tplContent)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &ChangelogBuilder{
 		cfg:                 cfg,
 		commitTypeToSection: commitTypeToSection,
 		tpl:                 tpl,
+		pullRequestTpl:      pullRequestTpl,
+		compareUrl:          cfg.CompareUrl,
+		commitUrl:           cfg.CommitUrl,
 	}, nil
 }
 
+// UseWebLinks sets the compare and commit URL patterns of the platform. The patterns in the config take precedence.
+func (builder *ChangelogBuilder) UseWebLinks(compareUrl string, commitUrl string) {
+	if builder.cfg.CompareUrl == "" {
+		builder.compareUrl = compareUrl
+	}
+
+	if builder.cfg.CommitUrl == "" {
+		builder.commitUrl = commitUrl
+	}
+}
+
+// loadTemplate parses the template file, or the fallback when there is no file, and validates it by executing it
+// with sample data - so a misspelled field fails right away instead of on the next release.
+func loadTemplate(name string, filePath string, fallback string) (*template.Template, error) {
+	content := fallback
+	if filePath != "" {
+		fileContent, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s template: %s with: %w", name, filePath, err)
+		}
+		content = string(fileContent)
+	}
+
+	tpl, err := template.New(name).Funcs(templateFuncs()).Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s template: %w", name, err)
+	}
+
+	if err := tpl.Execute(io.Discard, sampleData()); err != nil {
+		return nil, fmt.Errorf("%s template is not valid: %w", name, err)
+	}
+
+	return tpl, nil
+}
+
+func sampleData() Changelog {
+	return Changelog{
+		Version:         "1.1.0",
+		PreviousVersion: "1.0.0",
+		CompareUrl:      "http://example.com/compare/1.0.0...1.1.0",
+		Date:            "2024-01-20",
+		Sections: []TemplateSection{
+			{
+				Title: "Features",
+				Items: []SectionItem{
+					{
+						Title:       "a new endpoint",
+						HasLink:     true,
+						LinkPreview: "JIRA-1",
+						Link:        "http://example.com/JIRA-1",
+						Links:       []commits.IssueLink{{Text: "JIRA-1", Url: "http://example.com/JIRA-1"}},
+						Type:        "feat",
+						Scope:       "api",
+						ScopeName:   "api",
+						Sha:         "0123456789abcdef0123456789abcdef01234567",
+						ShortSha:    "0123456",
+						CommitUrl:   "http://example.com/commit/0123456789abcdef0123456789abcdef01234567",
+						Author:      "easy-release",
+						Body:        "a body",
+					},
+				},
+			},
+		},
+		Contributors: []string{"easy-release"},
+		ScopeStyle:   config.ScopeStyleNone,
+	}
+}
+
+// Generate renders the changelog entry of a release without a known previous version.
 func (builder *ChangelogBuilder) Generate(nextVersion string, extractedCommits []commits.Commit, date time.Time) ([]byte, error) {
+	data, err := builder.Data(nextVersion, "", "", extractedCommits, date)
+	if err != nil {
+		return nil, err
+	}
+
+	return builder.Changelog(data)
+}
+
+// Changelog renders the changelog entry with the changelog template.
+func (builder *ChangelogBuilder) Changelog(data Changelog) ([]byte, error) {
+	return execute(builder.tpl, data)
+}
+
+// PullRequest renders the release PR description with the pull request template (the changelog one by default).
+func (builder *ChangelogBuilder) PullRequest(data Changelog) ([]byte, error) {
+	return execute(builder.pullRequestTpl, data)
+}
+
+func execute(tpl *template.Template, data Changelog) ([]byte, error) {
 	var output bytes.Buffer
+
+	visible := data
+	visible.Sections = []TemplateSection{}
+	for _, section := range data.Sections {
+		if !section.Hidden {
+			visible.Sections = append(visible.Sections, section)
+		}
+	}
+
+	if err := tpl.Execute(&output, visible); err != nil {
+		return nil, err
+	}
+
+	return output.Bytes(), nil
+}
+
+// Data groups the commits into the configured sections. The hidden sections are kept and marked as such.
+// The tag prefix is put in front of the versions in the compare URL.
+func (builder *ChangelogBuilder) Data(nextVersion string, previousVersion string, tagPrefix string, extractedCommits []commits.Commit, date time.Time) (Changelog, error) {
 	templateData := Changelog{}
 	templateData.Version = nextVersion
+	templateData.PreviousVersion = previousVersion
+	if previousVersion != "" && builder.compareUrl != "" {
+		templateData.CompareUrl = strings.NewReplacer("{from}", tagPrefix+previousVersion, "{to}", tagPrefix+nextVersion).
+			Replace(builder.compareUrl)
+	}
 	templateData.Date = date.Format(time.DateOnly)
 	templateData.Sections = []TemplateSection{}
+	templateData.Contributors = []string{}
+	templateData.ScopeStyle = builder.cfg.ScopeStyle
 
 	templateSections := []*TemplateSection{}
 	sectionNameToTemplateSection := map[string]*TemplateSection{}
 
 	for _, section := range builder.cfg.ChangelogSections {
 		templateSection := TemplateSection{
-			Title: section.Section,
-			Items: []SectionItem{},
+			Title:  section.Section,
+			Hidden: section.Hidden,
+			Items:  []SectionItem{},
 		}
 		templateSections = append(templateSections, &templateSection)
 		sectionNameToTemplateSection[section.Section] = &templateSection
 	}
 
 	for _, ref := range extractedCommits {
-		changelogSection, ok := builder.commitTypeToSection[ref.Type]
+		changelogSection, ok := builder.sectionOf(ref)
 		if !ok {
 			//This commit type is not tracked in changelog, we skip it
 			continue
 		}
 
 		item := SectionItem{
-			Title: ref.Title,
+			Title:     ref.Title,
+			Type:      ref.Type,
+			Scope:     ref.Scope,
+			ScopeName: ref.Scope,
+			Sha:       ref.Sha,
+			ShortSha:  ref.Sha[:min(len(ref.Sha), shortShaLength)],
+			Author:    ref.Author,
+			Breaking:  ref.Breaking,
+			Body:      ref.Body,
 		}
 
-		if ref.Link != "" {
+		if ref.Sha != "" && builder.commitUrl != "" {
+			item.CommitUrl = strings.ReplaceAll(builder.commitUrl, "{sha}", ref.Sha)
+		}
+
+		if name, ok := builder.cfg.ScopeNames[ref.Scope]; ok {
+			item.ScopeName = name
+		}
+
+		item.Links = ref.Links
+		if len(item.Links) == 0 && ref.Link != "" {
+			// a commit that did not come from the parser
+			item.Links = []commits.IssueLink{{Text: ref.Link, Url: fmt.Sprintf("%s%s", builder.cfg.LinkPrefix, ref.Link)}}
+		}
+
+		if len(item.Links) > 0 {
 			item.HasLink = true
-			item.LinkPreview = ref.Link
-			item.Link = fmt.Sprintf("%s%s", builder.cfg.LinkPrefix, ref.Link)
+			item.LinkPreview = item.Links[0].Text
+			item.Link = item.Links[0].Url
 		}
 
 		templateSection, ok := sectionNameToTemplateSection[changelogSection.Section]
 		if !ok {
-			return nil, fmt.Errorf("this should not be happening! a template section exists that does not exist in the config!")
+			return Changelog{}, fmt.Errorf("this should not be happening! a template section exists that does not exist in the config!")
 		}
 
 		templateSection.Items = append(templateSection.Items, item)
+
+		if ref.Author != "" && !slices.Contains(templateData.Contributors, ref.Author) {
+			templateData.Contributors = append(templateData.Contributors, ref.Author)
+		}
 	}
 
 	for _, tplSec := range templateSections {
@@ -105,12 +291,53 @@ func (builder *ChangelogBuilder) Generate(nextVersion string, extractedCommits [
 			continue
 		}
 
+		tplSec.Groups = groupByScope(tplSec.Items)
 		templateData.Sections = append(templateData.Sections, *tplSec)
 	}
 
-	if err := builder.tpl.Execute(&output, templateData); err != nil {
-		return nil, err
+	return templateData, nil
+}
+
+func groupByScope(items []SectionItem) []ScopeGroup {
+	groups := []ScopeGroup{{Name: "", Items: []SectionItem{}}}
+	nameToIdx := map[string]int{"": 0}
+
+	for _, item := range items {
+		idx, ok := nameToIdx[item.ScopeName]
+		if !ok {
+			idx = len(groups)
+			nameToIdx[item.ScopeName] = idx
+			groups = append(groups, ScopeGroup{Name: item.ScopeName, Items: []SectionItem{}})
+		}
+
+		groups[idx].Items = append(groups[idx].Items, item)
 	}
 
-	return output.Bytes(), nil
+	if len(groups[0].Items) == 0 {
+		return groups[1:]
+	}
+
+	return groups
+}
+
+// sectionOf finds the section of the commit. A commit declared breaking only in its footer goes to the section of
+// `<type>!` or to the first section incrementing the major version.
+func (builder *ChangelogBuilder) sectionOf(commit commits.Commit) (*config.ChangelogSection, bool) {
+	if !commit.Breaking || strings.HasSuffix(commit.Type, "!") {
+		section, ok := builder.commitTypeToSection[commit.Type]
+		return section, ok
+	}
+
+	if section, ok := builder.commitTypeToSection[commit.Type+"!"]; ok {
+		return section, true
+	}
+
+	for idx, section := range builder.cfg.ChangelogSections {
+		if section.Increment == config.IncrementVersionMajor {
+			return &builder.cfg.ChangelogSections[idx], true
+		}
+	}
+
+	section, ok := builder.commitTypeToSection[commit.Type]
+	return section, ok
 }
@@ -2,6 +2,8 @@ package changelog
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -148,6 +150,358 @@ func (suite *ChangelogTestSuite) TestGenerate() {
 
 }
 
+func (suite *ChangelogTestSuite) TestGenerateWithBreakingFooter() {
+	actual, err := suite.builder.Generate("2.0.0", []commits.Commit{
+		{
+			Type:     "feat",
+			Title:    "new auth",
+			Breaking: true,
+		},
+		{
+			Type:     "refactor",
+			Title:    "removed the legacy api",
+			Breaking: true,
+		},
+		{
+			Type:  "feat",
+			Title: "a new endpoint",
+		},
+	}, time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC))
+
+	suite.NoError(err)
+	suite.Equal(`
+## 2.0.0 (2024-12-25)
+
+### Breaking Changes
+* new auth
+* removed the legacy api
+
+### Features
+* a new endpoint
+`, string(actual))
+}
+
+func (suite *ChangelogTestSuite) TestHiddenSections() {
+	cfg := config.Default()
+	cfg.ChangelogSections = append(cfg.ChangelogSections, config.ChangelogSection{
+		Section:   "Performance",
+		Hidden:    true,
+		Increment: config.IncrementVersionPatch,
+		Includes:  []string{"perf"},
+	})
+	commitTypeToSection, err := config.PivotSections(cfg)
+	suite.Require().NoError(err)
+	builder, err := NewBuilder(cfg, commitTypeToSection)
+	suite.Require().NoError(err)
+
+	data, err := builder.Data("1.0.1", "1.0.0", "", []commits.Commit{
+		{Type: "perf", Title: "faster startup"},
+		{Type: "fix", Title: "a bug"},
+	}, time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC))
+	suite.Require().NoError(err)
+
+	suite.Run("hidden sections are kept and marked in the data", func() {
+		suite.Require().Len(data.Sections, 2)
+		suite.Equal("Fixes", data.Sections[0].Title)
+		suite.False(data.Sections[0].Hidden)
+		suite.Equal("Performance", data.Sections[1].Title)
+		suite.True(data.Sections[1].Hidden)
+	})
+
+	suite.Run("hidden sections are left out of the changelog and PR description", func() {
+		expected := "\n## 1.0.1 (2024-01-20)\n\n### Fixes\n* a bug\n"
+
+		chnglog, err := builder.Changelog(data)
+		suite.Require().NoError(err)
+		suite.Equal(expected, string(chnglog))
+
+		description, err := builder.PullRequest(data)
+		suite.Require().NoError(err)
+		suite.Equal(expected, string(description))
+	})
+}
+
+func (suite *ChangelogTestSuite) TestScopeStyles() {
+	log := []commits.Commit{
+		{Type: "feat", Scope: "api", Title: "new endpoint", Link: "JIRA-001"},
+		{Type: "feat", Title: "unscoped feature"},
+		{Type: "feat", Scope: "ui", Title: "dark mode"},
+		{Type: "feat", Scope: "api", Title: "pagination"},
+		{Type: "fix", Scope: "ui", Title: "a bug"},
+	}
+	date := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+
+	builderWith := func(style string) *ChangelogBuilder {
+		cfg := config.Default()
+		cfg.ScopeStyle = style
+		cfg.ScopeNames = map[string]string{"api": "REST API"}
+		commitTypeToSection, err := config.PivotSections(cfg)
+		suite.Require().NoError(err)
+		builder, err := NewBuilder(cfg, commitTypeToSection)
+		suite.Require().NoError(err)
+
+		return builder
+	}
+
+	suite.Run("items are grouped by the scope name with the unscoped ones first", func() {
+		data, err := builderWith(config.ScopeStyleGroup).Data("1.1.0", "1.0.0", "", log, date)
+		suite.Require().NoError(err)
+
+		groups := data.Sections[0].Groups
+		suite.Require().Len(groups, 3)
+		suite.Equal("", groups[0].Name)
+		suite.Len(groups[0].Items, 1)
+		suite.Equal("REST API", groups[1].Name)
+		suite.Len(groups[1].Items, 2)
+		suite.Equal("api", groups[1].Items[0].Scope)
+		suite.Equal("ui", groups[2].Name)
+		suite.Len(groups[2].Items, 1)
+	})
+
+	suite.Run("NONE leaves the scope out", func() {
+		chnglog, err := builderWith(config.ScopeStyleNone).Generate("1.1.0", log, date)
+		suite.Require().NoError(err)
+		suite.Equal(`
+## 1.1.0 (2024-01-20)
+
+### Features
+* [JIRA-001](http://example.com/JIRA-001) new endpoint
+* unscoped feature
+* dark mode
+* pagination
+
+### Fixes
+* a bug
+`, string(chnglog))
+	})
+
+	suite.Run("PREFIX puts the scope name in bold before the item", func() {
+		chnglog, err := builderWith(config.ScopeStylePrefix).Generate("1.1.0", log, date)
+		suite.Require().NoError(err)
+		suite.Equal(`
+## 1.1.0 (2024-01-20)
+
+### Features
+* **REST API:** [JIRA-001](http://example.com/JIRA-001) new endpoint
+* unscoped feature
+* **ui:** dark mode
+* **REST API:** pagination
+
+### Fixes
+* **ui:** a bug
+`, string(chnglog))
+	})
+
+	suite.Run("GROUP puts a sub-heading per scope in every section", func() {
+		chnglog, err := builderWith(config.ScopeStyleGroup).Generate("1.1.0", log, date)
+		suite.Require().NoError(err)
+		suite.Equal(`
+## 1.1.0 (2024-01-20)
+
+### Features
+* unscoped feature
+
+#### REST API
+* [JIRA-001](http://example.com/JIRA-001) new endpoint
+* pagination
+
+#### ui
+* dark mode
+
+### Fixes
+
+#### ui
+* a bug
+`, string(chnglog))
+	})
+}
+
+func (suite *ChangelogTestSuite) TestMultipleLinks() {
+	data, err := suite.builder.Data("1.0.1", "1.0.0", "", []commits.Commit{
+		{
+			Type:  "fix",
+			Title: "crash on start (#12)",
+			Link:  "JIRA-7",
+			Links: []commits.IssueLink{
+				{Text: "JIRA-7", Url: "https://jira.example.com/browse/JIRA-7"},
+				{Text: "#12", Url: "https://github.com/owner/repo/issues/12"},
+			},
+		},
+	}, time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC))
+	suite.Require().NoError(err)
+
+	item := data.Sections[0].Items[0]
+	suite.True(item.HasLink)
+	suite.Equal("JIRA-7", item.LinkPreview)
+	suite.Equal("https://jira.example.com/browse/JIRA-7", item.Link)
+
+	chnglog, err := suite.builder.Changelog(data)
+	suite.Require().NoError(err)
+	suite.Equal("\n## 1.0.1 (2024-01-20)\n\n### Fixes\n"+
+		"* [JIRA-7](https://jira.example.com/browse/JIRA-7) [#12](https://github.com/owner/repo/issues/12) crash on start (#12)\n",
+		string(chnglog))
+}
+
+func (suite *ChangelogTestSuite) TestWebLinks() {
+	log := []commits.Commit{
+		{Type: "feat", Title: "new endpoint", Sha: "0123456789abcdef0123456789abcdef01234567"},
+		{Type: "fix", Title: "a bug without a sha"},
+	}
+	date := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+
+	builderWith := func(cfg *config.Config) *ChangelogBuilder {
+		commitTypeToSection, err := config.PivotSections(cfg)
+		suite.Require().NoError(err)
+		builder, err := NewBuilder(cfg, commitTypeToSection)
+		suite.Require().NoError(err)
+
+		return builder
+	}
+
+	suite.Run("the heading links to the compare URL and the items to their commits", func() {
+		builder := builderWith(config.Default())
+		builder.UseWebLinks("https://github.com/o/r/compare/{from}...{to}", "https://github.com/o/r/commit/{sha}")
+
+		data, err := builder.Data("1.1.0", "1.0.0", "api-", log, date)
+		suite.Require().NoError(err)
+		suite.Equal("https://github.com/o/r/compare/api-1.0.0...api-1.1.0", data.CompareUrl)
+
+		chnglog, err := builder.Changelog(data)
+		suite.Require().NoError(err)
+		suite.Equal(`
+## [1.1.0](https://github.com/o/r/compare/api-1.0.0...api-1.1.0) (2024-01-20)
+
+### Features
+* new endpoint ([0123456](https://github.com/o/r/commit/0123456789abcdef0123456789abcdef01234567))
+
+### Fixes
+* a bug without a sha
+`, string(chnglog))
+	})
+
+	suite.Run("the first release has nothing to compare to", func() {
+		builder := builderWith(config.Default())
+		builder.UseWebLinks("https://github.com/o/r/compare/{from}...{to}", "")
+
+		data, err := builder.Data("1.0.0", "", "", log, date)
+		suite.Require().NoError(err)
+		suite.Empty(data.CompareUrl)
+		suite.Empty(data.Sections[0].Items[0].CommitUrl)
+	})
+
+	suite.Run("the config patterns take precedence over the platform ones", func() {
+		cfg := config.Default()
+		cfg.CompareUrl = "https://git.example.com/diff/{from}/{to}"
+		cfg.CommitUrl = "https://git.example.com/c/{sha}"
+		builder := builderWith(cfg)
+		builder.UseWebLinks("https://github.com/o/r/compare/{from}...{to}", "https://github.com/o/r/commit/{sha}")
+
+		data, err := builder.Data("1.1.0", "1.0.0", "", log, date)
+		suite.Require().NoError(err)
+		suite.Equal("https://git.example.com/diff/1.0.0/1.1.0", data.CompareUrl)
+		suite.Equal("https://git.example.com/c/0123456789abcdef0123456789abcdef01234567", data.Sections[0].Items[0].CommitUrl)
+	})
+}
+
+func (suite *ChangelogTestSuite) TestUserTemplates() {
+	dir := suite.T().TempDir()
+	changelogTemplate := filepath.Join(dir, "changelog.tpl")
+	pullRequestTemplate := filepath.Join(dir, "pr.tpl")
+	suite.Require().NoError(os.WriteFile(changelogTemplate, []byte(`
+# [{{ .Version }}] - {{ date "02 Jan 2006" .Date }} (since {{ .PreviousVersion | default "the beginning" }})
+{{ range .Sections }}
+## {{ .Title | upper }}
+{{ range .Items }}- {{ if .Scope }}**{{ .Scope }}:** {{ end }}{{ .Title | title }} ({{ .ShortSha }}){{ if .Breaking }} BREAKING{{ end }}
+{{ end }}{{ end }}
+Contributors: {{ join ", " .Contributors }}
+`), 0644))
+	suite.Require().NoError(os.WriteFile(pullRequestTemplate, []byte(`Releasing {{ .Version }}`), 0644))
+
+	cfg := config.Default()
+	cfg.ChangelogTemplate = changelogTemplate
+	cfg.PullRequestTemplate = pullRequestTemplate
+	commitTypeToSection, err := config.PivotSections(cfg)
+	suite.Require().NoError(err)
+	builder, err := NewBuilder(cfg, commitTypeToSection)
+	suite.Require().NoError(err)
+
+	data, err := builder.Data("2.0.0", "1.4.2", "", []commits.Commit{
+		{
+			Type:     "feat",
+			Scope:    "api",
+			Title:    "new auth",
+			Sha:      "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678",
+			Author:   "Jane",
+			Breaking: true,
+		},
+		{
+			Type:   "fix",
+			Title:  "a bug",
+			Sha:    "ffffffffffffffffffffffffffffffffffffffff",
+			Author: "John",
+		},
+		{
+			Type:   "fix",
+			Title:  "another bug",
+			Sha:    "eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee",
+			Author: "Jane",
+		},
+	}, time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC))
+	suite.Require().NoError(err)
+
+	chnglog, err := builder.Changelog(data)
+	suite.Require().NoError(err)
+	suite.Equal(`
+# [2.0.0] - 25 Dec 2024 (since 1.4.2)
+
+## BREAKING CHANGES
+- **api:** New auth (a1b2c3d) BREAKING
+
+## FIXES
+- A bug (fffffff)
+- Another bug (eeeeeee)
+
+Contributors: Jane, John
+`, string(chnglog))
+
+	description, err := builder.PullRequest(data)
+	suite.Require().NoError(err)
+	suite.Equal("Releasing 2.0.0", string(description))
+}
+
+func (suite *ChangelogTestSuite) TestUserTemplatesAreValidated() {
+	dir := suite.T().TempDir()
+
+	suite.Run("the template file has to exist", func() {
+		cfg := config.Default()
+		cfg.ChangelogTemplate = filepath.Join(dir, "missing.tpl")
+
+		_, err := NewBuilder(cfg, map[string]*config.ChangelogSection{})
+
+		suite.Error(err)
+	})
+
+	suite.Run("the template has to parse", func() {
+		cfg := config.Default()
+		cfg.ChangelogTemplate = filepath.Join(dir, "broken.tpl")
+		suite.Require().NoError(os.WriteFile(cfg.ChangelogTemplate, []byte(`{{ range .Sections }}`), 0644))
+
+		_, err := NewBuilder(cfg, map[string]*config.ChangelogSection{})
+
+		suite.ErrorContains(err, "failed to parse changelog template")
+	})
+
+	suite.Run("the template can use only the documented data", func() {
+		cfg := config.Default()
+		cfg.PullRequestTemplate = filepath.Join(dir, "unknown-field.tpl")
+		suite.Require().NoError(os.WriteFile(cfg.PullRequestTemplate, []byte(`{{ .Release }}`), 0644))
+
+		_, err := NewBuilder(cfg, map[string]*config.ChangelogSection{})
+
+		suite.ErrorContains(err, "pull request template is not valid")
+	})
+}
+
 func TestChangelogTestSuite(t *testing.T) {
 	suite.Run(t, new(ChangelogTestSuite))
 }
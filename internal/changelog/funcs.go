@@ -0,0 +1,134 @@
+package changelog
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateFuncs is a small sprig-like helper set available in the changelog and pull request templates.
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"title":      title,
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix string, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix string, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old string, new string, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains":   func(substr string, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix string, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix string, s string) bool { return strings.HasSuffix(s, suffix) },
+		"repeat":     func(count int, s string) string { return strings.Repeat(s, count) },
+		"trunc":      trunc,
+		"indent":     indent,
+		"nindent":    func(spaces int, s string) string { return "\n" + indent(spaces, s) },
+		"split":      func(sep string, s string) []string { return strings.Split(s, sep) },
+		"join":       join,
+		"default":    defaultValue,
+		"empty":      empty,
+		"coalesce":   coalesce,
+		"list":       func(items ...interface{}) []interface{} { return items },
+		"uniq":       uniq,
+		"now":        time.Now,
+		"date":       date,
+	}
+}
+
+func title(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func trunc(length int, s string) string {
+	if length < 0 || len(s) <= length {
+		return s
+	}
+
+	return s[:length]
+}
+
+func indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+func join(sep string, items interface{}) string {
+	value := reflect.ValueOf(items)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return fmt.Sprint(items)
+	}
+
+	parts := make([]string, 0, value.Len())
+	for idx := range value.Len() {
+		parts = append(parts, fmt.Sprint(value.Index(idx).Interface()))
+	}
+
+	return strings.Join(parts, sep)
+}
+
+func empty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.String:
+		return reflected.Len() == 0
+	}
+
+	return reflected.IsZero()
+}
+
+func defaultValue(fallback interface{}, value interface{}) interface{} {
+	if empty(value) {
+		return fallback
+	}
+
+	return value
+}
+
+func coalesce(values ...interface{}) interface{} {
+	for _, value := range values {
+		if !empty(value) {
+			return value
+		}
+	}
+
+	return nil
+}
+
+func uniq(items []string) []string {
+	result := []string{}
+	for _, item := range items {
+		if !slices.Contains(result, item) {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+// date formats a time or a 2006-01-02 string with a go layout.
+func date(layout string, value interface{}) (string, error) {
+	switch typed := value.(type) {
+	case time.Time:
+		return typed.Format(layout), nil
+	case string:
+		parsed, err := time.Parse(time.DateOnly, typed)
+		if err != nil {
+			return "", fmt.Errorf("cannot parse date: %s with: %w", typed, err)
+		}
+		return parsed.Format(layout), nil
+	}
+
+	return "", fmt.Errorf("cannot format %v as a date", value)
+}
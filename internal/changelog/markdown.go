@@ -0,0 +1,156 @@
+package changelog
+
+import (
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// InsertionMarker is a line in the changelog below which the new entries are put.
+// Without it they are put above the first version heading.
+const InsertionMarker = "<!-- easy-release:insert -->"
+
+// versionHeading is a markdown heading of a changelog entry and the offset of its line in the changelog.
+type versionHeading struct {
+	version string
+	offset  int
+}
+
+// headingVersion returns the version of a `## 1.2.3 (date)` or `## [1.2.3](link) (date)` heading or "" if the line is
+// not one. Only the `##` level of the entries is looked at, so the `###` sections and `####` scope groups - even
+// `#### 3d` - are never taken for a version, and neither is `## [Unreleased]`.
+func headingVersion(line string) string {
+	rest, found := strings.CutPrefix(line, "## ")
+	if !found {
+		return ""
+	}
+
+	rest = strings.TrimSpace(rest)
+	title := ""
+	if strings.HasPrefix(rest, "[") {
+		end := strings.Index(rest, "]")
+		if end == -1 {
+			return ""
+		}
+
+		title = rest[1:end]
+	} else if fields := strings.Fields(rest); len(fields) > 0 {
+		title = fields[0]
+	}
+
+	return strictVersion(title)
+}
+
+// strictVersion returns the strict semantic version in a heading title - `1.2.3`, `v1.2.3` or the tag `api-1.2.3` -
+// or "" if there is none.
+func strictVersion(title string) string {
+	for idx := 0; idx < len(title); idx++ {
+		// the version starts the title or follows the separator of a tag prefix
+		if idx > 0 && !strings.ContainsRune("-/@_", rune(title[idx-1])) {
+			continue
+		}
+
+		candidate := strings.TrimPrefix(title[idx:], "v")
+		if _, err := semver.StrictNewVersion(candidate); err == nil {
+			return candidate
+		}
+	}
+
+	return ""
+}
+
+func versionHeadings(content string) []versionHeading {
+	result := []versionHeading{}
+	offset := 0
+
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if version := headingVersion(strings.TrimRight(line, "\r\n")); version != "" {
+			result = append(result, versionHeading{version: version, offset: offset})
+		}
+		offset += len(line)
+	}
+
+	return result
+}
+
+// ExtractSection returns the body of the changelog entry for the version without its heading.
+func ExtractSection(content []byte, version string) (string, bool) {
+	lines := strings.Split(string(content), "\n")
+	start := -1
+
+	for idx, line := range lines {
+		if start == -1 {
+			if headingVersion(line) == version {
+				start = idx + 1
+			}
+			continue
+		}
+
+		if headingVersion(line) != "" {
+			return strings.TrimSpace(strings.Join(lines[start:idx], "\n")), true
+		}
+	}
+
+	if start == -1 {
+		return "", false
+	}
+
+	return strings.TrimSpace(strings.Join(lines[start:], "\n")), true
+}
+
+// Header returns what is above the entries of the changelog - up to and including the InsertionMarker or up to the
+// first version heading.
+func Header(content []byte) []byte {
+	text := string(content)
+
+	if markerIdx := strings.Index(text, InsertionMarker); markerIdx != -1 {
+		return []byte(text[:markerIdx+len(InsertionMarker)] + "\n")
+	}
+
+	if headings := versionHeadings(text); len(headings) > 0 {
+		return []byte(text[:headings[0].offset])
+	}
+
+	return content
+}
+
+// Merge puts the entry of the version into the changelog. An existing entry of the same version is replaced,
+// otherwise the entry goes below the InsertionMarker or above the first version heading. Everything above is kept
+// as it is - titles, intros or an `Unreleased` section.
+func Merge(current []byte, entry []byte, version string) []byte {
+	content := string(current)
+	trimmedEntry := strings.Trim(string(entry), "\n")
+	headings := versionHeadings(content)
+
+	for idx, heading := range headings {
+		if heading.version != version {
+			continue
+		}
+
+		if idx+1 == len(headings) {
+			return []byte(content[:heading.offset] + trimmedEntry + "\n")
+		}
+
+		return []byte(content[:heading.offset] + trimmedEntry + "\n\n" + content[headings[idx+1].offset:])
+	}
+
+	if markerIdx := strings.Index(content, InsertionMarker); markerIdx != -1 {
+		afterMarker := markerIdx + len(InsertionMarker)
+		rest := strings.TrimLeft(content[afterMarker:], "\r\n")
+		if rest == "" {
+			return []byte(content[:afterMarker] + "\n\n" + trimmedEntry + "\n")
+		}
+
+		return []byte(content[:afterMarker] + "\n\n" + trimmedEntry + "\n\n" + rest)
+	}
+
+	if len(headings) > 0 {
+		return []byte(content[:headings[0].offset] + trimmedEntry + "\n\n" + content[headings[0].offset:])
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return entry
+	}
+
+	return []byte(strings.TrimRight(content, "\n") + "\n\n" + trimmedEntry + "\n")
+}
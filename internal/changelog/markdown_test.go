@@ -0,0 +1,185 @@
+package changelog
+
+func (suite *ChangelogTestSuite) TestExtractSection() {
+	content := []byte(`# Changelog
+
+## [1.1.0](https://github.com/org/repo/compare/1.0.0...1.1.0) (2024-08-12)
+
+### Features
+* a new endpoint
+
+## 1.0.0 (2024-01-20)
+
+### Fixes
+* a nasty bug
+`)
+
+	suite.Run("the latest entry", func() {
+		section, ok := ExtractSection(content, "1.1.0")
+		suite.True(ok)
+		suite.Equal("### Features\n* a new endpoint", section)
+	})
+
+	suite.Run("the last entry in the file", func() {
+		section, ok := ExtractSection(content, "1.0.0")
+		suite.True(ok)
+		suite.Equal("### Fixes\n* a nasty bug", section)
+	})
+
+	suite.Run("a missing entry", func() {
+		_, ok := ExtractSection(content, "1.0.1")
+		suite.False(ok)
+	})
+}
+
+func (suite *ChangelogTestSuite) TestHeadingVersion() {
+	tests := map[string]string{
+		"## 1.2.3 (2024-01-20)":              "1.2.3",
+		"## [1.2.3](link) (2024-01-20)":      "1.2.3",
+		"## v1.2.3":                          "1.2.3",
+		"## [api-1.2.3-rc.1](link)":          "1.2.3-rc.1",
+		"## web/v0.3.0":                      "0.3.0",
+		"## [Unreleased]":                    "",
+		"## 2":                               "",
+		"## 1.2":                             "",
+		"### 1.2.3":                          "",
+		"### v1":                             "",
+		"#### 2":                             "",
+		"#### 3d":                            "",
+		"# 1.2.3":                            "",
+		"##1.2.3":                            "",
+		"* 1.2.3 a list item, not a heading": "",
+	}
+
+	for line, version := range tests {
+		suite.Run(line, func() {
+			suite.Equal(version, headingVersion(line))
+		})
+	}
+}
+
+func (suite *ChangelogTestSuite) TestNestedNumericHeadings() {
+	content := []byte(`# Changelog
+
+## [1.1.0](link) (2024-08-12)
+
+### Features
+
+#### 3d
+* a 3d view
+
+#### 2
+* a second view
+
+## 1.0.0 (2024-01-20)
+
+### 1 fix
+* a nasty bug
+`)
+
+	suite.Run("are part of the entry", func() {
+		section, ok := ExtractSection(content, "1.1.0")
+		suite.True(ok)
+		suite.Equal("### Features\n\n#### 3d\n* a 3d view\n\n#### 2\n* a second view", section)
+	})
+
+	suite.Run("do not end up in the header", func() {
+		suite.Equal("# Changelog\n\n", string(Header(content)))
+	})
+
+	suite.Run("are replaced with their entry", func() {
+		merged := Merge(content, []byte("## 1.1.0 (2024-08-13)\n\n### Fixes\n* a bug\n"), "1.1.0")
+
+		suite.Equal(`# Changelog
+
+## 1.1.0 (2024-08-13)
+
+### Fixes
+* a bug
+
+## 1.0.0 (2024-01-20)
+
+### 1 fix
+* a nasty bug
+`, string(merged))
+	})
+}
+
+func (suite *ChangelogTestSuite) TestMerge() {
+	entry := []byte("\n## 1.2.0 (2024-09-01)\n\n### Features\n* another endpoint\n")
+
+	suite.Run("an empty changelog", func() {
+		suite.Equal(string(entry), string(Merge([]byte(""), entry, "1.2.0")))
+	})
+
+	suite.Run("a changelog made only of entries", func() {
+		current := "\n## 1.1.0 (2024-08-12)\n\n### Features\n* a new endpoint\n"
+
+		suite.Equal(string(entry)+current, string(Merge([]byte(current), entry, "1.2.0")))
+	})
+
+	suite.Run("the header and the unreleased section are kept on top", func() {
+		current := `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+## [Unreleased]
+
+## [1.1.0](https://github.com/org/repo/compare/1.0.0...1.1.0) (2024-08-12)
+
+### Features
+* a new endpoint
+`
+
+		suite.Equal(`# Changelog
+
+All notable changes to this project will be documented in this file.
+
+## [Unreleased]
+
+## 1.2.0 (2024-09-01)
+
+### Features
+* another endpoint
+
+## [1.1.0](https://github.com/org/repo/compare/1.0.0...1.1.0) (2024-08-12)
+
+### Features
+* a new endpoint
+`, string(Merge([]byte(current), entry, "1.2.0")))
+	})
+
+	suite.Run("the entry goes below the marker", func() {
+		current := "# Changelog\n<!-- easy-release:insert -->\n## Older releases\n\n## 1.1.0 (2024-08-12)\n"
+
+		suite.Equal("# Changelog\n<!-- easy-release:insert -->\n\n## 1.2.0 (2024-09-01)\n\n### Features\n* another endpoint\n\n## Older releases\n\n## 1.1.0 (2024-08-12)\n",
+			string(Merge([]byte(current), entry, "1.2.0")))
+	})
+
+	suite.Run("a header without entries", func() {
+		suite.Equal("# Changelog\n\n## 1.2.0 (2024-09-01)\n\n### Features\n* another endpoint\n",
+			string(Merge([]byte("# Changelog\n"), entry, "1.2.0")))
+	})
+
+	suite.Run("an existing entry of the version is replaced", func() {
+		current := "# Changelog\n\n## 1.2.0 (2024-08-30)\n\n### Fixes\n* a bug\n\n## 1.1.0 (2024-08-12)\n\n### Features\n* a new endpoint\n"
+
+		suite.Equal("# Changelog\n\n## 1.2.0 (2024-09-01)\n\n### Features\n* another endpoint\n\n## 1.1.0 (2024-08-12)\n\n### Features\n* a new endpoint\n",
+			string(Merge([]byte(current), entry, "1.2.0")))
+	})
+
+	suite.Run("an existing last entry of the version is replaced", func() {
+		current := "# Changelog\n\n## [1.2.0](link) (2024-08-30)\n\n### Fixes\n* a bug\n"
+
+		suite.Equal("# Changelog\n\n## 1.2.0 (2024-09-01)\n\n### Features\n* another endpoint\n",
+			string(Merge([]byte(current), entry, "1.2.0")))
+	})
+}
+
+func (suite *ChangelogTestSuite) TestHeader() {
+	suite.Equal("# Changelog\n\n## [Unreleased]\n\n",
+		string(Header([]byte("# Changelog\n\n## [Unreleased]\n\n## 1.0.0 (2024-01-20)\n* a fix\n"))))
+	suite.Equal("# Changelog\n<!-- easy-release:insert -->\n",
+		string(Header([]byte("# Changelog\n<!-- easy-release:insert -->\n\n## 1.0.0 (2024-01-20)\n"))))
+	suite.Equal("# Changelog\n", string(Header([]byte("# Changelog\n"))))
+}
@@ -0,0 +1,122 @@
+package changelog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/rikotsev/easy-release/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+var ErrUnknownNotesFormat = errors.New("unknown release notes format")
+
+// ReleaseNotes are the machine-readable release notes of all the packages released together.
+type ReleaseNotes struct {
+	Releases []PackageNotes `json:"releases" yaml:"releases"`
+}
+
+type PackageNotes struct {
+	Package         string         `json:"package,omitempty" yaml:"package,omitempty"` // empty for a repository without packages
+	Tag             string         `json:"tag" yaml:"tag"`
+	Version         string         `json:"version" yaml:"version"`
+	PreviousVersion string         `json:"previousVersion,omitempty" yaml:"previousVersion,omitempty"`
+	Date            string         `json:"date" yaml:"date"`
+	Sections        []NotesSection `json:"sections" yaml:"sections"`
+	Files           []string       `json:"files" yaml:"files"` // the files updated with the version
+}
+
+// NotesSection is a changelog section. Unlike in the changelog the hidden sections are kept with a marker.
+type NotesSection struct {
+	Title   string        `json:"title" yaml:"title"`
+	Hidden  bool          `json:"hidden" yaml:"hidden"`
+	Commits []NotesCommit `json:"commits" yaml:"commits"`
+}
+
+type NotesCommit struct {
+	Type     string      `json:"type" yaml:"type"`
+	Scope    string      `json:"scope,omitempty" yaml:"scope,omitempty"`
+	Title    string      `json:"title" yaml:"title"`
+	Sha      string      `json:"sha,omitempty" yaml:"sha,omitempty"`
+	Author   string      `json:"author,omitempty" yaml:"author,omitempty"`
+	Breaking bool        `json:"breaking" yaml:"breaking"`
+	Links    []NotesLink `json:"links" yaml:"links"`
+}
+
+type NotesLink struct {
+	Text string `json:"text" yaml:"text"`
+	Url  string `json:"url" yaml:"url"`
+}
+
+// Notes turns the changelog data of a package into its release notes.
+func Notes(packageName string, tag string, data Changelog, files []string) PackageNotes {
+	result := PackageNotes{
+		Package:         packageName,
+		Tag:             tag,
+		Version:         data.Version,
+		PreviousVersion: data.PreviousVersion,
+		Date:            data.Date,
+		Sections:        make([]NotesSection, 0, len(data.Sections)),
+		Files:           files,
+	}
+
+	for _, section := range data.Sections {
+		notesSection := NotesSection{
+			Title:   section.Title,
+			Hidden:  section.Hidden,
+			Commits: make([]NotesCommit, 0, len(section.Items)),
+		}
+
+		for _, item := range section.Items {
+			notesCommit := NotesCommit{
+				Type:     item.Type,
+				Scope:    item.Scope,
+				Title:    item.Title,
+				Sha:      item.Sha,
+				Author:   item.Author,
+				Breaking: item.Breaking,
+				Links:    make([]NotesLink, 0, len(item.Links)),
+			}
+			for _, link := range item.Links {
+				notesCommit.Links = append(notesCommit.Links, NotesLink{Text: link.Text, Url: link.Url})
+			}
+
+			notesSection.Commits = append(notesSection.Commits, notesCommit)
+		}
+
+		result.Sections = append(result.Sections, notesSection)
+	}
+
+	return result
+}
+
+// Encode renders the release notes in one of the configured formats - JSON or YAML.
+func (notes ReleaseNotes) Encode(format string) ([]byte, error) {
+	switch format {
+	case config.ReleaseNotesJson:
+		content, err := json.MarshalIndent(notes, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode release notes as json with: %w", err)
+		}
+
+		return append(content, '\n'), nil
+	case config.ReleaseNotesYaml:
+		content, err := yaml.Marshal(notes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode release notes as yaml with: %w", err)
+		}
+
+		return content, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownNotesFormat, format)
+}
+
+// NotesFileName is the file the release notes in the format are written to.
+func NotesFileName(format string) string {
+	if format == config.ReleaseNotesYaml {
+		return "release-notes.yaml"
+	}
+
+	return "release-notes.json"
+}
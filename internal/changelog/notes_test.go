@@ -0,0 +1,91 @@
+package changelog
+
+import (
+	"time"
+
+	"github.com/rikotsev/easy-release/internal/commits"
+	"github.com/rikotsev/easy-release/internal/config"
+)
+
+func (suite *ChangelogTestSuite) TestNotes() {
+	cfg := config.Default()
+	cfg.ChangelogSections = append(cfg.ChangelogSections, config.ChangelogSection{
+		Section:   "Performance",
+		Hidden:    true,
+		Increment: config.IncrementVersionPatch,
+		Includes:  []string{"perf"},
+	})
+	commitTypeToSection, err := config.PivotSections(cfg)
+	suite.Require().NoError(err)
+	builder, err := NewBuilder(cfg, commitTypeToSection)
+	suite.Require().NoError(err)
+
+	data, err := builder.Data("1.1.0", "1.0.0", "api-", []commits.Commit{
+		{Type: "feat", Scope: "auth", Title: "a new endpoint", Sha: "0123456", Author: "Jane Doe", Link: "JIRA-1"},
+		{Type: "perf", Title: "faster startup"},
+	}, time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC))
+	suite.Require().NoError(err)
+
+	notes := ReleaseNotes{Releases: []PackageNotes{Notes("api", "api-1.1.0", data, []string{"CHANGELOG.md", "pom.xml"})}}
+
+	suite.Run("hidden sections are kept with a marker", func() {
+		sections := notes.Releases[0].Sections
+		suite.Require().Len(sections, 2)
+		suite.Equal("Features", sections[0].Title)
+		suite.False(sections[0].Hidden)
+		suite.Equal("Performance", sections[1].Title)
+		suite.True(sections[1].Hidden)
+	})
+
+	suite.Run("json", func() {
+		content, err := notes.Encode(config.ReleaseNotesJson)
+		suite.Require().NoError(err)
+		suite.JSONEq(`{
+  "releases": [
+    {
+      "package": "api",
+      "tag": "api-1.1.0",
+      "version": "1.1.0",
+      "previousVersion": "1.0.0",
+      "date": "2024-01-20",
+      "sections": [
+        {
+          "title": "Features",
+          "hidden": false,
+          "commits": [
+            {
+              "type": "feat",
+              "scope": "auth",
+              "title": "a new endpoint",
+              "sha": "0123456",
+              "author": "Jane Doe",
+              "breaking": false,
+              "links": [{"text": "JIRA-1", "url": "http://example.com/JIRA-1"}]
+            }
+          ]
+        },
+        {
+          "title": "Performance",
+          "hidden": true,
+          "commits": [{"type": "perf", "title": "faster startup", "breaking": false, "links": []}]
+        }
+      ],
+      "files": ["CHANGELOG.md", "pom.xml"]
+    }
+  ]
+}`, string(content))
+	})
+
+	suite.Run("yaml", func() {
+		content, err := notes.Encode(config.ReleaseNotesYaml)
+		suite.Require().NoError(err)
+		suite.Contains(string(content), "releases:\n    - package: api\n      tag: api-1.1.0\n")
+		suite.Contains(string(content), "hidden: true\n")
+		suite.Contains(string(content), "url: http://example.com/JIRA-1\n")
+	})
+
+	suite.Run("an unknown format", func() {
+		_, err := notes.Encode("XML")
+		suite.ErrorIs(err, ErrUnknownNotesFormat)
+	})
+}
@@ -1,18 +1,22 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"fmt"
-	"io"
 	"os/exec"
 	"strings"
+	"time"
 
+	"github.com/rikotsev/easy-release/internal/commits"
 	"github.com/rikotsev/easy-release/internal/config"
 )
 
 type CommandLineClient interface {
 	Tags(context.Context) ([]string, error)
-	Log(context.Context, string) ([]string, error)
+	TagDates(context.Context) (map[string]time.Time, error)
+	Log(context.Context, string, ...string) ([]string, error)
+	LogBetween(context.Context, string, string, ...string) ([]string, error)
 }
 
 func New(cfg *config.Config) CommandLineClient {
@@ -35,55 +39,78 @@ func (client *commandLineClientImpl) Tags(ctx context.Context) ([]string, error)
 	return strings.Split(string(stdout), "\n"), nil
 }
 
-func (client *commandLineClientImpl) Log(ctx context.Context, startingSha string) ([]string, error) {
+// TagDates returns the creation date of every tag - the tagger date of annotated tags and the commit date of the others.
+func (client *commandLineClientImpl) TagDates(ctx context.Context) (map[string]time.Time, error) {
+	stdout, _, err := client.runSync(ctx, client.cfg.GitCommand, "for-each-ref", "--format=%(refname:strip=2)%09%(creatordate:short)", "refs/tags")
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]time.Time{}
+	for _, line := range strings.Split(strings.TrimSpace(string(stdout)), "\n") {
+		tag, rawDate, found := strings.Cut(line, "\t")
+		if !found {
+			continue
+		}
+
+		date, err := time.Parse(time.DateOnly, rawDate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the date of tag: %s with: %w", tag, err)
+		}
+
+		result[tag] = date
+	}
+
+	return result, nil
+}
+
+// Log returns the commits after startingSha in the commits.LogFormat. When paths are given only the commits touching them are returned.
+func (client *commandLineClientImpl) Log(ctx context.Context, startingSha string, paths ...string) ([]string, error) {
+	return client.LogBetween(ctx, startingSha, "HEAD", paths...)
+}
+
+// LogBetween returns the commits after fromRef up to and including toRef. An empty fromRef means from the first commit.
+func (client *commandLineClientImpl) LogBetween(ctx context.Context, fromRef string, toRef string, paths ...string) ([]string, error) {
 	args := []string{}
 	args = append(args, "log")
-	if startingSha != "" {
-		args = append(args, startingSha+"..HEAD")
+	if fromRef != "" {
+		args = append(args, fromRef+".."+toRef)
+	} else {
+		args = append(args, toRef)
+	}
+	// the messages span multiple lines, so they are separated with NUL
+	args = append(args, "-z", "--pretty=format:"+commits.LogFormat)
+	if len(paths) > 0 {
+		args = append(args, "--")
+		args = append(args, paths...)
 	}
-	args = append(args, "--pretty=format:%s")
 
 	stdout, _, err := client.runSync(ctx, client.cfg.GitCommand, args...)
 	if err != nil {
 		return nil, err
 	}
 
-	return strings.Split(string(stdout), "\n"), nil
+	return strings.Split(string(stdout), "\x00"), nil
 }
 
+// runSync runs the command and returns what it printed. Both outputs are read while the command runs, so a command
+// printing more than the pipe buffer - a long log - does not block on a full pipe.
 func (client *commandLineClientImpl) runSync(ctx context.Context, externalCmd string, args ...string) ([]byte, []byte, error) {
 	cmd := exec.CommandContext(ctx, externalCmd, args...)
 
-	errPipe, err := cmd.StderrPipe()
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to acquire stderr pipe: %w", err)
-	}
-	outPipe, err := cmd.StdoutPipe()
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to acquire stdout pipe: %w", err)
-	}
-
-	if err := cmd.Start(); err != nil {
-		return nil, nil, fmt.Errorf("failed to execute `%s %s` with: %w", externalCmd, strings.Join(args, " "), err)
-	}
-
-	stderr, err := io.ReadAll(errPipe)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read stderr: %w", err)
-	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
 
-	if len(stderr) > 0 {
-		return nil, nil, fmt.Errorf("command was not executed successfully. output was: %s", string(stderr))
-	}
+	runErr := cmd.Run()
 
-	stdout, err := io.ReadAll(outPipe)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read stdout: %w", err)
+	if stderr.Len() > 0 {
+		return nil, nil, fmt.Errorf("command was not executed successfully. output was: %s", stderr.String())
 	}
 
-	if err := cmd.Wait(); err != nil {
-		return nil, nil, fmt.Errorf("command did not run successfully: %w", err)
+	if runErr != nil {
+		return nil, nil, fmt.Errorf("failed to execute `%s %s` with: %w", externalCmd, strings.Join(args, " "), runErr)
 	}
 
-	return stdout, stderr, nil
+	return stdout.Bytes(), stderr.Bytes(), nil
 }
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rikotsev/easy-release/internal/config"
+	"github.com/stretchr/testify/suite"
+)
+
+type CliTestSuite struct {
+	suite.Suite
+}
+
+func (s *CliTestSuite) git(args ...string) {
+	cmd := exec.Command("git", append([]string{"-c", "user.name=Jane Doe", "-c", "user.email=jane@example.com"}, args...)...)
+	output, err := cmd.CombinedOutput()
+	s.Require().NoError(err, string(output))
+}
+
+func (s *CliTestSuite) TestLogBiggerThanThePipeBuffer() {
+	s.T().Chdir(s.T().TempDir())
+	s.git("init", "-q")
+
+	body := strings.Repeat("a long line of the commit body\n", 70)
+	for idx := 0; idx < 80; idx++ {
+		s.git("commit", "-q", "--allow-empty", "-m", fmt.Sprintf("feat: change %d\n\n%s", idx, body))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	logEntries, err := New(config.Default()).Log(ctx, "")
+
+	s.Require().NoError(err)
+	s.Len(logEntries, 80)
+	s.Contains(logEntries[0], "feat: change 79")
+}
+
+func (s *CliTestSuite) TestFailingCommand() {
+	s.T().Chdir(s.T().TempDir())
+
+	_, err := New(config.Default()).Log(context.Background(), "")
+
+	s.Error(err)
+}
+
+func TestCliTestSuite(t *testing.T) {
+	suite.Run(t, new(CliTestSuite))
+}
@@ -14,15 +14,63 @@ import (
 
 var CannotParseErr = errors.New("could not parse commit from raw log")
 
+// footerRegex matches a git trailer style footer - `Refs: #123`, `Closes #42` or `BREAKING CHANGE: the api is gone`.
+var footerRegex = regexp.MustCompile(`^(BREAKING CHANGE|[\w-]+)(?:: | #)(.*)$`)
+
+const (
+	breakingChangeFooter        = "BREAKING CHANGE"
+	breakingChangeFooterSynonym = "BREAKING-CHANGE"
+	// ReleaseAsFooter forces the next version - `Release-As: 3.0.0`.
+	ReleaseAsFooter = "Release-As"
+	// LogFormat is the `git log` format of a raw log entry - the sha, the author and the whole message.
+	// Entries without the separator are treated as a bare message.
+	LogFormat         = "%H%x1f%an%x1f%B"
+	logFieldSeparator = "\x1f"
+)
+
 type CommitParser struct {
 	cfg          *config.Config
 	extractRegex *regexp.Regexp
+	linkRules    []linkRule
+}
+
+type linkRule struct {
+	pattern *regexp.Regexp
+	url     string
 }
 
 type Commit struct {
-	Title string
-	Type  string
-	Link  string
+	Title    string
+	Type     string
+	Scope    string
+	Link     string      // the [] capture of the subject
+	Links    []IssueLink // the links of the [] capture, the subject and the footers
+	Sha      string
+	Author   string
+	Body     string   // the message without the subject and the footers
+	Footers  []Footer // the footers in the last paragraph of the message
+	Breaking bool     // `!` after the type or a BREAKING CHANGE footer
+}
+
+type Footer struct {
+	Token string
+	Value string
+}
+
+type IssueLink struct {
+	Text string // the reference as written in the commit - `JIRA-123`, `#42`
+	Url  string
+}
+
+// Footer returns the value of the first footer with the token. Tokens are matched case-insensitively.
+func (c Commit) Footer(token string) (string, bool) {
+	for _, footer := range c.Footers {
+		if strings.EqualFold(footer.Token, token) {
+			return footer.Value, true
+		}
+	}
+
+	return "", false
 }
 
 type CommitLinter struct {
@@ -34,9 +82,20 @@ func NewParser(cfg *config.Config) (*CommitParser, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could not compile regex: %s with %w", cfg.ExtractCommitRegex, err)
 	}
+	linkRules := make([]linkRule, 0, len(cfg.LinkRules))
+	for _, rule := range cfg.LinkRules {
+		compiledPattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("could not compile link rule pattern: %s with %w", rule.Pattern, err)
+		}
+
+		linkRules = append(linkRules, linkRule{pattern: compiledPattern, url: rule.Url})
+	}
+
 	return &CommitParser{
 		cfg:          cfg,
 		extractRegex: compiledRegex,
+		linkRules:    linkRules,
 	}, nil
 }
 
@@ -51,20 +110,144 @@ func NewLinter(cfg *config.Config) (*CommitLinter, error) {
 	}, nil
 }
 
+// extract parses a raw log entry. The subject is matched against the configured regex and the rest of the
+// message is split into a body and footers.
 func (parser *CommitParser) extract(rawLog string) (Commit, error) {
-	matches := parser.extractRegex.FindStringSubmatch(rawLog)
+	sha, author, message := "", "", rawLog
+	if fields := strings.SplitN(rawLog, logFieldSeparator, 3); len(fields) == 3 {
+		sha, author, message = strings.TrimSpace(fields[0]), fields[1], fields[2]
+	}
+
+	subject, rest, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	matches := parser.extractRegex.FindStringSubmatch(strings.TrimSpace(subject))
 
 	if len(matches) > 5 {
-		return Commit{
-			Type:  fmt.Sprintf("%s%s", matches[1], matches[3]),
-			Link:  matches[4],
-			Title: matches[5],
-		}, nil
+		commit := Commit{
+			Type:     fmt.Sprintf("%s%s", matches[1], matches[3]),
+			Scope:    matches[2],
+			Link:     matches[4],
+			Title:    matches[5],
+			Sha:      sha,
+			Author:   author,
+			Breaking: matches[3] == "!",
+		}
+		commit.Body, commit.Footers = parseBody(rest)
+		commit.Links = parser.links(commit.Link, commit.Title, footerParagraph(rest, commit.Footers))
+
+		for _, footer := range commit.Footers {
+			if footer.Token == breakingChangeFooter || footer.Token == breakingChangeFooterSynonym {
+				commit.Breaking = true
+			}
+		}
+
+		return commit, nil
 	}
 
 	return Commit{}, CannotParseErr
 }
 
+// parseBody splits what follows the subject into a body and footers. Footers are only looked for in the last paragraph
+// and a line that is not a footer continues the value of the previous one.
+func parseBody(rest string) (string, []Footer) {
+	paragraphs := paragraphsOf(rest)
+	last := paragraphs[len(paragraphs)-1]
+	lines := strings.Split(last, "\n")
+
+	if !footerRegex.MatchString(lines[0]) {
+		return strings.TrimSpace(rest), []Footer{}
+	}
+
+	footers := []Footer{}
+	for _, line := range lines {
+		if matches := footerRegex.FindStringSubmatch(line); matches != nil {
+			footers = append(footers, Footer{Token: matches[1], Value: matches[2]})
+			continue
+		}
+
+		footers[len(footers)-1].Value += "\n" + line
+	}
+
+	return strings.TrimSpace(strings.Join(paragraphs[:len(paragraphs)-1], "\n\n")), footers
+}
+
+func paragraphsOf(rest string) []string {
+	return strings.Split(strings.TrimSpace(strings.ReplaceAll(rest, "\r\n", "\n")), "\n\n")
+}
+
+// footerParagraph is the paragraph the footers were parsed from or "" when there are no footers.
+func footerParagraph(rest string, footers []Footer) string {
+	if len(footers) == 0 {
+		return ""
+	}
+
+	paragraphs := paragraphsOf(rest)
+
+	return paragraphs[len(paragraphs)-1]
+}
+
+// links applies the link rules to the [] capture, the subject and the footers. A [] capture that no rule matches is
+// linked with the link prefix. The same link is kept only once.
+func (parser *CommitParser) links(capture string, subject string, footers string) []IssueLink {
+	result := []IssueLink{}
+	add := func(link IssueLink) {
+		if !slices.Contains(result, link) {
+			result = append(result, link)
+		}
+	}
+
+	captureLinks := parser.applyLinkRules(capture)
+	if capture != "" && len(captureLinks) == 0 {
+		captureLinks = append(captureLinks, IssueLink{Text: capture, Url: parser.cfg.LinkPrefix + capture})
+	}
+
+	for _, links := range [][]IssueLink{captureLinks, parser.applyLinkRules(subject), parser.applyLinkRules(footers)} {
+		for _, link := range links {
+			add(link)
+		}
+	}
+
+	return result
+}
+
+// applyLinkRules returns the links in the order of the references in the text.
+// A reference matched by a rule can't be matched by the rules after it - `AB#1` is not also `#1`.
+func (parser *CommitParser) applyLinkRules(text string) []IssueLink {
+	type match struct {
+		start, end int
+		link       IssueLink
+	}
+	matches := []match{}
+
+	for _, rule := range parser.linkRules {
+		for _, idx := range rule.pattern.FindAllStringSubmatchIndex(text, -1) {
+			overlaps := slices.ContainsFunc(matches, func(m match) bool {
+				return idx[0] < m.end && m.start < idx[1]
+			})
+			if overlaps {
+				continue
+			}
+
+			matches = append(matches, match{
+				start: idx[0],
+				end:   idx[1],
+				link: IssueLink{
+					Text: strings.TrimSpace(text[idx[0]:idx[1]]),
+					Url:  string(rule.pattern.ExpandString(nil, rule.url, text, idx)),
+				},
+			})
+		}
+	}
+
+	slices.SortFunc(matches, func(a, b match) int { return a.start - b.start })
+
+	result := make([]IssueLink, 0, len(matches))
+	for _, m := range matches {
+		result = append(result, m.link)
+	}
+
+	return result
+}
+
 func (parser *CommitParser) Extract(ctx context.Context, rawLogEntries []string) []Commit {
 
 	result := []Commit{}
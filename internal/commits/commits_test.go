@@ -237,6 +237,117 @@ func (suite *CommitsTestSuite) TestLint() {
 	}
 }
 
+func (suite *CommitsTestSuite) TestParseLogRecord() {
+	commit, err := suite.parser.extract("a1b2c3d4\x1fJane Doe\x1ffeat(api): [JIRA-1] new endpoint\n\nwith a body\n")
+
+	suite.NoError(err)
+	suite.Equal(Commit{
+		Title:   "new endpoint",
+		Type:    "feat",
+		Scope:   "api",
+		Link:    "JIRA-1",
+		Links:   []IssueLink{{Text: "JIRA-1", Url: "http://example.com/JIRA-1"}},
+		Sha:     "a1b2c3d4",
+		Author:  "Jane Doe",
+		Body:    "with a body",
+		Footers: []Footer{},
+	}, commit)
+}
+
+func (suite *CommitsTestSuite) TestParseBodyAndFooters() {
+	suite.Run("subject only", func() {
+		commit, err := suite.parser.extract("feat: [JIRA-1] new endpoint\n")
+
+		suite.NoError(err)
+		suite.Equal("new endpoint", commit.Title)
+		suite.Equal("", commit.Body)
+		suite.Empty(commit.Footers)
+		suite.False(commit.Breaking)
+	})
+
+	suite.Run("body without footers", func() {
+		commit, err := suite.parser.extract("fix: a bug\n\nThe cache was never invalidated.\n\nNow it is: on every write.")
+
+		suite.NoError(err)
+		suite.Equal("The cache was never invalidated.\n\nNow it is: on every write.", commit.Body)
+		suite.Empty(commit.Footers)
+		suite.False(commit.Breaking)
+	})
+
+	suite.Run("breaking change footer", func() {
+		commit, err := suite.parser.extract("feat: [JIRA-2] new auth (#12)\n\n* the login is rewritten\n\n" +
+			"BREAKING CHANGE: the session endpoint is removed\n  use the token endpoint instead\nRefs #12")
+
+		suite.NoError(err)
+		suite.Equal("feat", commit.Type)
+		suite.Equal("JIRA-2", commit.Link)
+		suite.Equal("* the login is rewritten", commit.Body)
+		suite.Equal([]Footer{
+			{Token: "BREAKING CHANGE", Value: "the session endpoint is removed\n  use the token endpoint instead"},
+			{Token: "Refs", Value: "12"},
+		}, commit.Footers)
+		suite.True(commit.Breaking)
+	})
+
+	suite.Run("breaking change footer synonym", func() {
+		commit, err := suite.parser.extract("fix: a bug\r\n\r\nBREAKING-CHANGE: the default is different")
+
+		suite.NoError(err)
+		suite.True(commit.Breaking)
+	})
+
+	suite.Run("exclamation mark is breaking", func() {
+		commit, err := suite.parser.extract("feat!: new auth")
+
+		suite.NoError(err)
+		suite.True(commit.Breaking)
+	})
+}
+
+func (suite *CommitsTestSuite) TestLinkRules() {
+	cfg := config.Default()
+	cfg.LinkRules = []config.LinkRule{
+		{Pattern: `AB#(\d+)`, Url: "https://dev.azure.com/org/project/_workitems/edit/$1"},
+		{Pattern: `#(\d+)`, Url: "https://github.com/owner/repo/issues/$1"},
+		{Pattern: `\b([A-Z]+-\d+)\b`, Url: "https://jira.example.com/browse/$1"},
+	}
+	parser, err := NewParser(cfg)
+	suite.Require().NoError(err)
+
+	suite.Run("the capture, the subject and the footers are linked", func() {
+		commit, err := parser.extract("fix: [JIRA-7] crash on start (#12)\n\nRefs #12\nFixes: AB#456")
+
+		suite.NoError(err)
+		suite.Equal([]IssueLink{
+			{Text: "JIRA-7", Url: "https://jira.example.com/browse/JIRA-7"},
+			{Text: "#12", Url: "https://github.com/owner/repo/issues/12"},
+			{Text: "AB#456", Url: "https://dev.azure.com/org/project/_workitems/edit/456"},
+		}, commit.Links)
+	})
+
+	suite.Run("a capture no rule matches is linked with the prefix", func() {
+		commit, err := parser.extract("fix: [ticket 7] crash on start")
+
+		suite.NoError(err)
+		suite.Equal([]IssueLink{{Text: "ticket 7", Url: "http://example.com/ticket 7"}}, commit.Links)
+	})
+
+	suite.Run("a commit without references has no links", func() {
+		commit, err := parser.extract("fix: crash on start")
+
+		suite.NoError(err)
+		suite.Empty(commit.Links)
+	})
+
+	suite.Run("an invalid pattern fails the parser", func() {
+		cfg := config.Default()
+		cfg.LinkRules = []config.LinkRule{{Pattern: `(`, Url: "https://example.com/$1"}}
+
+		_, err := NewParser(cfg)
+		suite.Error(err)
+	})
+}
+
 func TestCommitsTestSuite(t *testing.T) {
 	suite.Run(t, new(CommitsTestSuite))
 }
@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"path"
 )
 
 var ErrDuplicateType = errors.New("duplicated commit type in section")
+var ErrInvalidPackage = errors.New("invalid package")
+var ErrInvalidReleaseNotes = errors.New("invalid release notes format")
 
 type Config struct {
 	GitCommand           string             `json:"gitCommand,omitempty"`
@@ -16,6 +19,7 @@ type Config struct {
 	StartingVersion      string             `json:"startingVersion,omitempty"`
 	ExtractCommitRegex   string             `json:"extractCommitRegex,omitempty"`
 	LinkPrefix           string             `json:"linkPrefix,omitempty"`
+	LinkRules            []LinkRule         `json:"linkRules,omitempty"` // applied in order - a reference matched by a rule is not matched by the next ones
 	ReleaseCommitPrefix  string             `json:"releaseCommitPrefix,omitempty"`
 	SnapshotCommitPrefix string             `json:"snapshotCommitPrefix,omitempty"`
 	ChangelogPath        string             `json:"changelogPath,omitempty"`
@@ -23,6 +27,17 @@ type Config struct {
 	ChangelogSections    []ChangelogSection `json:"changelogSections,omitempty"` //the order here will be applied in the resulting changelog
 	Updates              []Update           `json:"updates,omitempty"`
 	PrLint               PrLint             `json:"prLint,omitempty"`
+	Release              Release            `json:"release,omitempty"`
+	Packages             []Package          `json:"packages,omitempty"`             // when set - the repository is a monorepo and every package is released on its own
+	SeparatePullRequests bool               `json:"separatePullRequests,omitempty"` // one release PR per package instead of a combined one
+	PrereleaseBranches   map[string]string  `json:"prereleaseBranches,omitempty"`   // base branch to prerelease channel - `{"next": "rc"}`
+	ChangelogTemplate    string             `json:"changelogTemplate,omitempty"`    // path to a text/template file for the changelog entry
+	PullRequestTemplate  string             `json:"pullRequestTemplate,omitempty"`  // path to a text/template file for the PR description - defaults to the changelog one
+	ScopeStyle           string             `json:"scopeStyle,omitempty"`           // NONE, PREFIX (`**api:** item`) or GROUP (a sub-heading per scope in every section)
+	ScopeNames           map[string]string  `json:"scopeNames,omitempty"`           // scope to the name displayed in the changelog - `{"api": "REST API"}`
+	CompareUrl           string             `json:"compareUrl,omitempty"`           // `{from}` and `{to}` are replaced with tags - derived from the platform when empty
+	CommitUrl            string             `json:"commitUrl,omitempty"`            // `{sha}` is replaced with the commit sha - derived from the platform when empty
+	ReleaseNotes         []string           `json:"releaseNotes,omitempty"`         // JSON and/or YAML - written to release-notes.json / release-notes.yaml
 }
 
 type ChangelogSection struct {
@@ -34,10 +49,44 @@ type ChangelogSection struct {
 
 type Update struct {
 	FilePath string `json:"filePath,omitempty"`
-	Kind     string `json:"kind,omitempty"` //Only supports Maven
+	Kind     string `json:"kind,omitempty"` // MAVEN, YAML, PACKAGE_JSON, TOML, REGEX, MARKER, GRADLE, HELM_CHART, DOTNET or PYTHON
 	PomPath  string `json:"pomPath,omitempty"`
 	YamlPath string `json:"yamlPath,omitempty"`
 	TomlPath string `json:"tomlPath,omitempty"`
+	Pattern  string `json:"pattern,omitempty"` // REGEX - every match is updated
+	Group    int    `json:"group,omitempty"`   // REGEX - the group of the pattern holding the version, 1 by default
+	Marker   string `json:"marker,omitempty"`  // MARKER - the annotation of the lines to update, x-easy-release-version by default
+	// HELM_CHART - RELEASE sets the chart version to the released one (the default), PATCH versions the chart
+	// independently by bumping its patch on every release and NONE leaves it as is
+	ChartVersion   string `json:"chartVersion,omitempty"`
+	KeepAppVersion bool   `json:"keepAppVersion,omitempty"` // HELM_CHART - leave the appVersion as is
+	// DOTNET - the MSBuild properties to update, Version by default. AssemblyVersion and FileVersion get the four-part version
+	MsbuildProperties []string `json:"msbuildProperties,omitempty"`
+}
+
+// LinkRule turns every match of the pattern in the [] capture, the subject and the footers of a commit into a link.
+// The url can refer to the groups of the pattern - `{"pattern": "#(\\d+)", "url": "https://github.com/owner/repo/issues/$1"}`.
+type LinkRule struct {
+	Pattern string `json:"pattern,omitempty"`
+	Url     string `json:"url,omitempty"`
+}
+
+// Package is a separately versioned part of a monorepo.
+// Commits are attributed to a package by the files they touch under Path and the update file paths are relative to Path.
+type Package struct {
+	Name          string   `json:"name,omitempty"` // defaults to the last element of the path
+	Path          string   `json:"path,omitempty"`
+	TagPrefix     string   `json:"tagPrefix,omitempty"`     // defaults to `<name>-`
+	ChangelogPath string   `json:"changelogPath,omitempty"` // defaults to `<path>/CHANGELOG.md`
+	Updates       []Update `json:"updates,omitempty"`
+}
+
+// Release configures the release published next to the tag on the platforms that support it (currently GitHub).
+type Release struct {
+	Enabled    bool   `json:"enabled,omitempty"`
+	Draft      bool   `json:"draft,omitempty"`
+	Prerelease bool   `json:"prerelease,omitempty"` // prerelease versions are always marked as such
+	MakeLatest string `json:"makeLatest,omitempty"` // possible values - true, false, legacy. Empty leaves it to the platform
 }
 
 type PrLint struct {
@@ -55,6 +104,20 @@ const (
 	UpdateKindYaml        = "YAML"
 	UpdateKindPackageJson = "PACKAGE_JSON"
 	UpdateKindToml        = "TOML"
+	UpdateKindRegex       = "REGEX"
+	UpdateKindMarker      = "MARKER"
+	UpdateKindGradle      = "GRADLE"
+	UpdateKindHelmChart   = "HELM_CHART"
+	UpdateKindDotnet      = "DOTNET"
+	UpdateKindPython      = "PYTHON"
+	ChartVersionRelease   = "RELEASE"
+	ChartVersionPatch     = "PATCH"
+	ChartVersionNone      = "NONE"
+	ScopeStyleNone        = "NONE"
+	ScopeStylePrefix      = "PREFIX"
+	ScopeStyleGroup       = "GROUP"
+	ReleaseNotesJson      = "JSON"
+	ReleaseNotesYaml      = "YAML"
 )
 
 func LoadConfig() (*Config, error) {
@@ -73,9 +136,24 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %s with %w", configFileName, err)
 	}
 
+	if err = Validate(result); err != nil {
+		return nil, fmt.Errorf("invalid config file: %s with %w", configFileName, err)
+	}
+
 	return result, nil
 }
 
+// Validate checks the values that are only used after a release has made changes, so a typo fails before any of them.
+func Validate(cfg *Config) error {
+	for _, format := range cfg.ReleaseNotes {
+		if format != ReleaseNotesJson && format != ReleaseNotesYaml {
+			return fmt.Errorf("%w: %s - use %s or %s", ErrInvalidReleaseNotes, format, ReleaseNotesJson, ReleaseNotesYaml)
+		}
+	}
+
+	return nil
+}
+
 func Default() *Config {
 	return &Config{
 		GitCommand:      "git",
@@ -95,6 +173,7 @@ func Default() *Config {
 		SnapshotCommitPrefix: "chore(snapshot): ",
 		ChangelogPath:        "CHANGELOG.md",
 		ReleaseBranchPrefix:  "easy-release--",
+		ScopeStyle:           ScopeStyleNone,
 		ChangelogSections: []ChangelogSection{
 			{
 				Section:   "Breaking Changes",
@@ -162,3 +241,55 @@ func PivotSections(cfg *Config) (map[string]*ChangelogSection, error) {
 
 	return result, nil
 }
+
+// ResolvePackages returns the packages to be released with their defaults applied.
+// A repository without packages is released as a single unnamed package made of the top level config.
+func ResolvePackages(cfg *Config) ([]Package, error) {
+	if len(cfg.Packages) == 0 {
+		return []Package{
+			{
+				ChangelogPath: cfg.ChangelogPath,
+				Updates:       cfg.Updates,
+			},
+		}, nil
+	}
+
+	result := make([]Package, 0, len(cfg.Packages))
+	names := map[string]bool{}
+
+	for idx, pkg := range cfg.Packages {
+		if pkg.Path == "" {
+			return nil, fmt.Errorf("%w: package [%d] has no path", ErrInvalidPackage, idx)
+		}
+
+		resolved := Package{
+			Name:          pkg.Name,
+			Path:          path.Clean(pkg.Path),
+			TagPrefix:     pkg.TagPrefix,
+			ChangelogPath: pkg.ChangelogPath,
+			Updates:       make([]Update, 0, len(pkg.Updates)),
+		}
+		if resolved.Name == "" {
+			resolved.Name = path.Base(resolved.Path)
+		}
+		if resolved.TagPrefix == "" {
+			resolved.TagPrefix = resolved.Name + "-"
+		}
+		if resolved.ChangelogPath == "" {
+			resolved.ChangelogPath = path.Join(resolved.Path, "CHANGELOG.md")
+		}
+		for _, upd := range pkg.Updates {
+			upd.FilePath = path.Join(resolved.Path, upd.FilePath)
+			resolved.Updates = append(resolved.Updates, upd)
+		}
+
+		if names[resolved.Name] {
+			return nil, fmt.Errorf("%w: package name %s is duplicated", ErrInvalidPackage, resolved.Name)
+		}
+		names[resolved.Name] = true
+
+		result = append(result, resolved)
+	}
+
+	return result, nil
+}
@@ -70,3 +70,83 @@ func TestPartialConfigLoad(t *testing.T) {
 	assert.Equal(t, ".*", cfg.ExtractCommitRegex)
 	assert.Equal(t, 3, len(cfg.ChangelogSections))
 }
+
+func TestValidate(t *testing.T) {
+	t.Run("the defaults are valid", func(t *testing.T) {
+		assert.NoError(t, Validate(Default()))
+	})
+
+	t.Run("release notes formats", func(t *testing.T) {
+		cfg := Default()
+		cfg.ReleaseNotes = []string{ReleaseNotesJson, ReleaseNotesYaml}
+		assert.NoError(t, Validate(cfg))
+
+		cfg.ReleaseNotes = []string{"json"}
+		assert.ErrorIs(t, Validate(cfg), ErrInvalidReleaseNotes)
+	})
+}
+
+func TestResolvePackages(t *testing.T) {
+	t.Run("a repository without packages is a single package", func(t *testing.T) {
+		cfg := Default()
+
+		packages, err := ResolvePackages(cfg)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []Package{{ChangelogPath: "CHANGELOG.md", Updates: cfg.Updates}}, packages)
+	})
+
+	t.Run("defaults are derived from the path", func(t *testing.T) {
+		cfg := Default()
+		cfg.Packages = []Package{
+			{
+				Path:    "packages/api/",
+				Updates: []Update{{FilePath: "pom.xml", Kind: UpdateKindMaven}},
+			},
+			{
+				Name:          "frontend",
+				Path:          "packages/web",
+				TagPrefix:     "web/v",
+				ChangelogPath: "docs/WEB_CHANGELOG.md",
+			},
+		}
+
+		packages, err := ResolvePackages(cfg)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []Package{
+			{
+				Name:          "api",
+				Path:          "packages/api",
+				TagPrefix:     "api-",
+				ChangelogPath: "packages/api/CHANGELOG.md",
+				Updates:       []Update{{FilePath: "packages/api/pom.xml", Kind: UpdateKindMaven}},
+			},
+			{
+				Name:          "frontend",
+				Path:          "packages/web",
+				TagPrefix:     "web/v",
+				ChangelogPath: "docs/WEB_CHANGELOG.md",
+				Updates:       []Update{},
+			},
+		}, packages)
+	})
+
+	t.Run("a package needs a path", func(t *testing.T) {
+		cfg := Default()
+		cfg.Packages = []Package{{Name: "api"}}
+
+		_, err := ResolvePackages(cfg)
+
+		assert.ErrorIs(t, err, ErrInvalidPackage)
+	})
+
+	t.Run("package names are unique", func(t *testing.T) {
+		cfg := Default()
+		cfg.Packages = []Package{{Path: "a/api"}, {Path: "b/api"}}
+
+		_, err := ResolvePackages(cfg)
+
+		assert.ErrorIs(t, err, ErrInvalidPackage)
+	})
+}
@@ -0,0 +1,171 @@
+package strategy
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rikotsev/easy-release/internal/changelog"
+	"github.com/rikotsev/easy-release/internal/cli"
+	"github.com/rikotsev/easy-release/internal/commits"
+	"github.com/rikotsev/easy-release/internal/config"
+	"github.com/rikotsev/easy-release/internal/vcs"
+	"github.com/rikotsev/easy-release/internal/version"
+	"github.com/stretchr/testify/suite"
+)
+
+// LocalReleaseTestSuite runs the whole release flow against a bare repository through the local api.
+type LocalReleaseTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	remote string
+	work   string
+	args   *EasyReleaseArgs
+	appCtx *EasyReleaseContext
+}
+
+func (s *LocalReleaseTestSuite) SetupTest() {
+	if _, err := exec.LookPath("git"); err != nil {
+		s.T().Skip("git binary is not available")
+	}
+
+	s.ctx = context.Background()
+	root := s.T().TempDir()
+	s.remote = filepath.Join(root, "remote.git")
+	s.work = filepath.Join(root, "work")
+
+	s.git(root, "init", "--bare", "--initial-branch=master", s.remote)
+	s.git(root, "clone", s.remote, s.work)
+	s.T().Chdir(s.work)
+
+	s.Require().NoError(os.WriteFile("CHANGELOG.md", []byte(""), 0644))
+	s.git(s.work, "add", "CHANGELOG.md")
+	s.git(s.work, "commit", "-m", "chore: initial commit")
+	s.git(s.work, "tag", "1.0.0")
+	s.git(s.work, "commit", "--allow-empty", "-m", "feat: [JIRA-1] a new endpoint")
+	s.git(s.work, "commit", "--allow-empty", "-m", "fix: a nasty bug", "-m", "The retries were never stopped.")
+	s.git(s.work, "push", "origin", "master", "--tags")
+
+	cfg := config.Default()
+	cfg.Updates = []config.Update{}
+	sections, err := config.PivotSections(cfg)
+	s.Require().NoError(err)
+	versionManager, err := version.New(cfg, sections)
+	s.Require().NoError(err)
+	commitParser, err := commits.NewParser(cfg)
+	s.Require().NoError(err)
+	changelogBuilder, err := changelog.NewBuilder(cfg, sections)
+	s.Require().NoError(err)
+	api, err := vcs.NewLocal(cfg, vcs.ApiOpts{Branch: "master"})
+	s.Require().NoError(err)
+
+	s.args = &EasyReleaseArgs{
+		Vcs:    Local,
+		Branch: "master",
+	}
+	s.appCtx = &EasyReleaseContext{
+		Cfg:                 cfg,
+		CommitTypeToSection: sections,
+		Git:                 cli.New(cfg),
+		VersionManager:      versionManager,
+		CommitParser:        commitParser,
+		ChangelogBuilder:    changelogBuilder,
+		Api:                 api,
+	}
+}
+
+func (s *LocalReleaseTestSuite) git(dir string, args ...string) string {
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	s.Require().NoError(err, string(output))
+
+	return strings.TrimSpace(string(output))
+}
+
+func (s *LocalReleaseTestSuite) TestPrepareAndPerformRelease() {
+	result, err := PerformRelease(s.args, s.appCtx).Execute(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal(NotApplicable, result)
+
+	result, err = PrepareRelease(s.args, s.appCtx).Execute(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal(Done, result)
+
+	releaseSubject := s.git(s.remote, "log", "-1", "--format=%s", "easy-release--master")
+	s.Equal("chore(release): 1.1.0", releaseSubject)
+	releaseChangelog := s.git(s.remote, "show", "easy-release--master:CHANGELOG.md")
+	s.Contains(releaseChangelog, "## 1.1.0")
+	s.Contains(releaseChangelog, "[JIRA-1](http://example.com/JIRA-1) a new endpoint")
+	s.Equal("", s.git(s.work, "status", "--porcelain", "--untracked-files=no"), "the working tree should not be touched")
+
+	description, err := os.ReadFile(vcs.LocalDescriptionFile)
+	s.Require().NoError(err)
+	s.Contains(string(description), "# chore(release): 1.1.0")
+
+	// somebody merges the release branch
+	s.git(s.work, "fetch", "origin")
+	s.git(s.work, "merge", "--ff-only", "origin/easy-release--master")
+	s.git(s.work, "push", "origin", "master")
+
+	result, err = PerformRelease(s.args, s.appCtx).Execute(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal(Done, result)
+
+	s.Contains(strings.Split(s.git(s.remote, "tag"), "\n"), "1.1.0")
+	s.Equal("tag", s.git(s.remote, "cat-file", "-t", "1.1.0"))
+}
+
+func (s *LocalReleaseTestSuite) TestBreakingChangeFooterBumpsMajor() {
+	s.git(s.work, "commit", "--allow-empty", "-m", "feat: new auth", "-m", "BREAKING CHANGE: the session endpoint is removed")
+	s.git(s.work, "push", "origin", "master")
+
+	result, err := PrepareRelease(s.args, s.appCtx).Execute(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal(Done, result)
+
+	s.Equal("chore(release): 2.0.0", s.git(s.remote, "log", "-1", "--format=%s", "easy-release--master"))
+	releaseChangelog := s.git(s.remote, "show", "easy-release--master:CHANGELOG.md")
+	s.Contains(releaseChangelog, "### Breaking Changes\n* new auth")
+}
+
+func (s *LocalReleaseTestSuite) TestRebuildChangelog() {
+	s.git(s.work, "tag", "-a", "1.1.0", "-m", "1.1.0")
+	s.Require().NoError(os.WriteFile("CHANGELOG.md", []byte("# Changelog\n\nAll notable changes.\n\n## 0.0.1 (2020-01-01)\n* stale\n"), 0644))
+	today := time.Now().Format(time.DateOnly)
+
+	result, err := RebuildChangelog(&ChangelogArgs{Rebuild: true}, s.appCtx).Execute(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal(Done, result)
+
+	content, err := os.ReadFile("CHANGELOG.md")
+	s.Require().NoError(err)
+	s.Equal("# Changelog\n\nAll notable changes.\n\n"+
+		"## 1.1.0 ("+today+")\n\n### Features\n* [JIRA-1](http://example.com/JIRA-1) a new endpoint\n\n### Fixes\n* a nasty bug\n\n"+
+		"## 1.0.0 ("+today+")\n", string(content))
+}
+
+func (s *LocalReleaseTestSuite) TestRebuildChangelogLinksToThePlatform() {
+	s.git(s.work, "tag", "-a", "1.1.0", "-m", "1.1.0")
+	args := &ChangelogArgs{Rebuild: true, Vcs: Github, Project: "owner", Repo: "repo"}
+	appCtx, err := CreateChangelogContext(args)
+	s.Require().NoError(err)
+
+	result, err := RebuildChangelog(args, appCtx).Execute(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal(Done, result)
+
+	content, err := os.ReadFile("CHANGELOG.md")
+	s.Require().NoError(err)
+	s.Contains(string(content), "## [1.1.0](https://github.com/owner/repo/compare/1.0.0...1.1.0)")
+	s.Contains(string(content), "* a nasty bug ([")
+	s.Contains(string(content), "](https://github.com/owner/repo/commit/")
+}
+
+func TestLocalReleaseTestSuite(t *testing.T) {
+	suite.Run(t, new(LocalReleaseTestSuite))
+}
@@ -2,23 +2,40 @@ package strategy
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/rikotsev/easy-release/internal/changelog"
 	"github.com/rikotsev/easy-release/internal/config"
 	"github.com/rikotsev/easy-release/internal/update"
 	"github.com/rikotsev/easy-release/internal/vcs"
+	"github.com/rikotsev/easy-release/internal/version"
 )
 
+// ErrUnknownPackage is returned when a release commit mentions a package that is not in the config.
+var ErrUnknownPackage = errors.New("release commit mentions an unknown package")
+
 type PerformReleaseImpl struct {
-	args            *EasyReleaseArgs
-	appCtx          *EasyReleaseContext
-	baseBranch      string
-	releaseSha      string
-	releasedVersion *semver.Version
+	args       *EasyReleaseArgs
+	appCtx     *EasyReleaseContext
+	baseBranch string
+	releaseSha string
+	released   []releasedPackage
+}
+
+// releasedPackage is a package with the version it was released in.
+type releasedPackage struct {
+	pkg     config.Package
+	version *semver.Version
+}
+
+func (r releasedPackage) tag() string {
+	return r.pkg.TagPrefix + r.version.String()
 }
 
 func PerformRelease(args *EasyReleaseArgs, applicationContext *EasyReleaseContext) Strategy {
@@ -30,6 +47,10 @@ func PerformRelease(args *EasyReleaseArgs, applicationContext *EasyReleaseContex
 }
 
 func (strat *PerformReleaseImpl) Execute(ctx context.Context) (StrategyResult, error) {
+	if err := config.Validate(strat.appCtx.Cfg); err != nil {
+		return Error, err
+	}
+
 	sha, message, err := strat.appCtx.Api.GetLastCommitMessage(ctx, strat.baseBranch)
 	if err != nil {
 		return Error, fmt.Errorf("failed to retrieve last ref for: %s with: %w", strat.baseBranch, err)
@@ -47,56 +68,240 @@ func (strat *PerformReleaseImpl) Execute(ctx context.Context) (StrategyResult, e
 	}
 
 	strat.releaseSha = sha
-	strat.releasedVersion, err = extractSemVerFromTitle(extractedCommits[0].Title)
+	strat.released, err = strat.extractReleasedPackages(extractedCommits[0].Title)
 	if err != nil {
-		return Error, fmt.Errorf("committed version - %s is not strict semver: %w", extractedCommits[0].Title, err)
+		return Error, err
 	}
 
-	if err := strat.appCtx.Api.CreateAnnotatedTag(ctx, sha, strat.releasedVersion.String()); err != nil {
-		return Error, fmt.Errorf("failed to create tag: %w", err)
+	for _, released := range strat.released {
+		if err := strat.appCtx.Api.CreateAnnotatedTag(ctx, sha, released.tag()); err != nil {
+			return Error, fmt.Errorf("failed to create tag: %w", err)
+		}
+
+		if err := strat.optionallyPublishRelease(ctx, released); err != nil {
+			return Error, fmt.Errorf("failed to publish release: %w", err)
+		}
 	}
 
 	if err := strat.optionallyMakeSnapshot(ctx); err != nil {
 		return Error, fmt.Errorf("failed to make snapshot: %w", err)
 	}
 
-	if err := strat.touchVersion(strat.releasedVersion.String()); err != nil {
+	if err := strat.touchVersion(); err != nil {
 		return Error, fmt.Errorf("failed to make version file: %w", err)
 	}
 
+	if err := strat.writeReleaseNotes(ctx); err != nil {
+		return Error, fmt.Errorf("failed to write release notes: %w", err)
+	}
+
 	return Done, nil
 }
 
+// extractReleasedPackages parses the title of a release commit - `1.2.0` or `api@1.2.0, web@0.3.0`.
+func (strat *PerformReleaseImpl) extractReleasedPackages(title string) ([]releasedPackage, error) {
+	packages, err := config.ResolvePackages(strat.appCtx.Cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve packages: %w", err)
+	}
+
+	if len(strat.appCtx.Cfg.Packages) == 0 {
+		releasedVersion, err := extractSemVerFromTitle(title)
+		if err != nil {
+			return nil, fmt.Errorf("committed version - %s is not strict semver: %w", title, err)
+		}
+
+		return []releasedPackage{{pkg: packages[0], version: releasedVersion}}, nil
+	}
+
+	byName := make(map[string]config.Package, len(packages))
+	for _, pkg := range packages {
+		byName[pkg.Name] = pkg
+	}
+
+	result := []releasedPackage{}
+	for _, item := range strings.Split(title, ",") {
+		name, rawVersion, found := strings.Cut(strings.TrimSpace(item), packageNameSeparator)
+		if !found {
+			return nil, fmt.Errorf("committed package version - %s is not in the form <name>%s<version>", item, packageNameSeparator)
+		}
+
+		pkg, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPackage, name)
+		}
+
+		releasedVersion, err := extractSemVerFromTitle(rawVersion)
+		if err != nil {
+			return nil, fmt.Errorf("committed version - %s is not strict semver: %w", item, err)
+		}
+
+		result = append(result, releasedPackage{pkg: pkg, version: releasedVersion})
+	}
+
+	return result, nil
+}
+
+func (strat *PerformReleaseImpl) optionallyPublishRelease(ctx context.Context, released releasedPackage) error {
+	if !strat.appCtx.Cfg.Release.Enabled {
+		return nil
+	}
+
+	publisher, ok := strat.appCtx.Api.(vcs.ReleasePublisher)
+	if !ok {
+		slog.Warn("releases are enabled but the vcs platform does not support them. skipping", "vcs", strat.args.Vcs)
+		return nil
+	}
+
+	version := released.version.String()
+	currentChangelog, err := os.ReadFile(released.pkg.ChangelogPath)
+	if err != nil {
+		return fmt.Errorf("failed to read changelog: %s with: %w", released.pkg.ChangelogPath, err)
+	}
+
+	notes, ok := changelog.ExtractSection(currentChangelog, version)
+	if !ok {
+		slog.Warn("the changelog has no entry for the released version. the release will have no notes", "version", version)
+	}
+
+	err = publisher.CreateRelease(ctx, released.tag(), version, notes)
+	if errors.Is(err, vcs.ErrReleasesNotSupported) {
+		slog.Warn("releases are enabled but the vcs platform does not support them. skipping", "vcs", strat.args.Vcs)
+		return nil
+	}
+
+	return err
+}
+
 func (strat *PerformReleaseImpl) optionallyMakeSnapshot(ctx context.Context) error {
-	snapshotVersion := fmt.Sprintf("%s-%s", strat.releasedVersion.IncPatch().String(), "SNAPSHOT")
-
-	changes := []vcs.RemoteChange{}
-	for idx, upd := range strat.appCtx.Cfg.Updates {
-		if upd.Kind == config.UpdateKindMaven {
-			filePath, content, err := update.Execute(snapshotVersion, upd)
-			if err != nil {
-				return fmt.Errorf("could not update file: %s [%d] with: %w", upd.FilePath, idx, err)
+	// a file shared by several packages is updated by all of them and pushed once
+	snapshotFiles := []update.UpdatedFile{}
+	snapshotVersions := []string{}
+
+	for _, released := range strat.released {
+		snapshotVersion := fmt.Sprintf("%s-%s", released.version.IncPatch().String(), "SNAPSHOT")
+
+		snapshotUpdates := []config.Update{}
+		for _, upd := range released.pkg.Updates {
+			if upd.Kind == config.UpdateKindMaven || upd.Kind == config.UpdateKindGradle {
+				snapshotUpdates = append(snapshotUpdates, upd)
+			}
+		}
+
+		if len(snapshotUpdates) == 0 {
+			continue
+		}
+
+		updatedFiles, err := update.ExecuteOn(snapshotFiles, snapshotVersion, snapshotUpdates)
+		if err != nil {
+			return fmt.Errorf("could not update files with: %w", err)
+		}
+
+		for _, updatedFile := range updatedFiles {
+			replaced := false
+			for idx := range snapshotFiles {
+				if snapshotFiles[idx].Path == updatedFile.Path {
+					snapshotFiles[idx] = updatedFile
+					replaced = true
+				}
+			}
+			if !replaced {
+				snapshotFiles = append(snapshotFiles, updatedFile)
 			}
+		}
 
-			changes = append(changes, vcs.RemoteChange{
-				Path:    filePath,
-				Content: string(content),
-			})
+		if released.pkg.Name == "" {
+			snapshotVersions = append(snapshotVersions, snapshotVersion)
+		} else {
+			snapshotVersions = append(snapshotVersions, released.pkg.Name+packageNameSeparator+snapshotVersion)
 		}
 	}
 
-	if len(changes) == 0 {
-		//There are no maven projects to be bumped
+	if len(snapshotFiles) == 0 {
+		//There are no maven or gradle projects to be bumped
 		return nil
 	}
 
-	message := fmt.Sprintf("%s%s", strat.appCtx.Cfg.SnapshotCommitPrefix, snapshotVersion)
+	changes := make([]vcs.RemoteChange, 0, len(snapshotFiles))
+	for _, snapshotFile := range snapshotFiles {
+		changes = append(changes, vcs.RemoteChange{
+			Path:    snapshotFile.Path,
+			Content: string(snapshotFile.Content),
+		})
+	}
+
+	message := fmt.Sprintf("%s%s", strat.appCtx.Cfg.SnapshotCommitPrefix, strings.Join(snapshotVersions, ", "))
 
 	return strat.appCtx.Api.PushCommit(ctx, strat.baseBranch, strat.releaseSha, message, changes)
 }
 
-func (strat *PerformReleaseImpl) touchVersion(version string) error {
-	return os.WriteFile(".easy-release-version.txt", []byte(version), 0644)
+// touchVersion writes the released version, or the created tags one per line for a monorepo.
+func (strat *PerformReleaseImpl) touchVersion() error {
+	if strat.args.DryRun {
+		// the version file signals the pipeline that a release happened
+		return nil
+	}
+
+	tags := make([]string, 0, len(strat.released))
+	for _, released := range strat.released {
+		tags = append(tags, released.tag())
+	}
+
+	return os.WriteFile(".easy-release-version.txt", []byte(strings.Join(tags, "\n")), 0644)
+}
+
+// writeReleaseNotes writes the notes of the released packages. The commits are read from the local history between
+// the previous stable version and the release commit, so the release commit has to be checked out.
+func (strat *PerformReleaseImpl) writeReleaseNotes(ctx context.Context) error {
+	if len(strat.appCtx.Cfg.ReleaseNotes) == 0 || strat.args.DryRun {
+		return nil
+	}
+
+	tags, err := strat.appCtx.Git.Tags(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	notes := changelog.ReleaseNotes{Releases: []changelog.PackageNotes{}}
+	for _, released := range strat.released {
+		previousVersion := ""
+		for _, stable := range stableVersions(version.TagsWithPrefix(tags, released.pkg.TagPrefix)) {
+			if semver.MustParse(stable).LessThan(released.version) {
+				previousVersion = stable
+			}
+		}
+
+		previousRef := ""
+		if previousVersion != "" {
+			previousRef = released.pkg.TagPrefix + previousVersion
+		}
+
+		paths := []string{}
+		if released.pkg.Path != "" {
+			paths = append(paths, released.pkg.Path)
+		}
+
+		logEntries, err := strat.appCtx.Git.LogBetween(ctx, previousRef, strat.releaseSha, paths...)
+		if err != nil {
+			return fmt.Errorf("failed to get log entries of: %s with: %w", released.tag(), err)
+		}
+
+		data, err := strat.appCtx.ChangelogBuilder.Data(released.version.String(), previousVersion, released.pkg.TagPrefix,
+			strat.appCtx.CommitParser.Extract(ctx, logEntries), time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to generate release notes of: %s with: %w", released.tag(), err)
+		}
+
+		updatedPaths, err := update.Paths(released.pkg.Updates)
+		if err != nil {
+			return fmt.Errorf("failed to find the files updated in: %s with: %w", released.tag(), err)
+		}
+		files := append([]string{released.pkg.ChangelogPath}, updatedPaths...)
+
+		notes.Releases = append(notes.Releases, changelog.Notes(released.pkg.Name, released.tag(), data, files))
+	}
+
+	return writeReleaseNotes(strat.appCtx.Cfg, notes)
 }
 
 func extractSemVerFromTitle(input string) (*semver.Version, error) {
@@ -1,10 +1,17 @@
 package strategy
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"testing"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/rikotsev/easy-release/internal/changelog"
+	"github.com/rikotsev/easy-release/internal/commits"
+	"github.com/rikotsev/easy-release/internal/config"
 )
 
 func TestExtractSemVerFromTitle(t *testing.T) {
@@ -49,3 +56,346 @@ func TestExtractSemVerFromTitle(t *testing.T) {
 	}
 
 }
+
+func TestPerformReleasePublishesRelease(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cfg := config.Default()
+	cfg.Updates = []config.Update{}
+	cfg.Release.Enabled = true
+	commitParser, err := commits.NewParser(cfg)
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	changelogContent := "\n## 1.1.0 (2024-08-12)\n\n### Features\n* a new endpoint\n\n## 1.0.0 (2024-01-20)\n\n### Fixes\n* a fix\n"
+	if err := os.WriteFile(cfg.ChangelogPath, []byte(changelogContent), 0644); err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	api := &mockReleaseApi{
+		mockApi: &mockApi{
+			lastCommitSha:     "release-sha",
+			lastCommitMessage: "chore(release): 1.1.0",
+		},
+		releases: map[string]string{},
+	}
+	strategy := PerformRelease(&EasyReleaseArgs{Branch: "master"}, &EasyReleaseContext{
+		Cfg:          cfg,
+		CommitParser: commitParser,
+		Api:          api,
+	})
+
+	result, err := strategy.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	if result != Done {
+		t.Errorf("expected: %s, got: %s", Done, result)
+	}
+
+	if len(api.tags) != 1 || api.tags[0] != "1.1.0" {
+		t.Errorf("expected the tag 1.1.0, got: %v", api.tags)
+	}
+
+	if notes := api.releases["1.1.0"]; notes != "### Features\n* a new endpoint" {
+		t.Errorf("unexpected release notes: %q", notes)
+	}
+}
+
+func TestPerformReleaseTagsEveryReleasedPackage(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cfg := config.Default()
+	cfg.Packages = []config.Package{
+		{Path: "packages/api"},
+		{Path: "packages/web", TagPrefix: "web/v"},
+	}
+	commitParser, err := commits.NewParser(cfg)
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	api := &mockApi{
+		lastCommitSha:     "release-sha",
+		lastCommitMessage: "chore(release): api@1.3.0, web@0.3.1 (#8)",
+	}
+	strategy := PerformRelease(&EasyReleaseArgs{Branch: "master"}, &EasyReleaseContext{
+		Cfg:          cfg,
+		CommitParser: commitParser,
+		Api:          api,
+	})
+
+	result, err := strategy.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	if result != Done {
+		t.Errorf("expected: %s, got: %s", Done, result)
+	}
+
+	if len(api.tags) != 2 || api.tags[0] != "api-1.3.0" || api.tags[1] != "web/v0.3.1" {
+		t.Errorf("expected the tags api-1.3.0 and web/v0.3.1, got: %v", api.tags)
+	}
+
+	versionFile, err := os.ReadFile(".easy-release-version.txt")
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	if string(versionFile) != "api-1.3.0\nweb/v0.3.1" {
+		t.Errorf("unexpected version file: %q", versionFile)
+	}
+}
+
+func TestPerformReleasePublishesPrefixedPrerelease(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cfg := config.Default()
+	cfg.Updates = []config.Update{}
+	cfg.Release.Enabled = true
+	cfg.Packages = []config.Package{
+		{Path: "packages/api"},
+	}
+	commitParser, err := commits.NewParser(cfg)
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	if err := os.MkdirAll("packages/api", 0755); err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	changelogContent := "\n## 2.0.0-rc.1 (2024-08-12)\n\n### Features\n* a new endpoint\n"
+	if err := os.WriteFile("packages/api/CHANGELOG.md", []byte(changelogContent), 0644); err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	api := &mockReleaseApi{
+		mockApi: &mockApi{
+			lastCommitSha:     "release-sha",
+			lastCommitMessage: "chore(release): api@2.0.0-rc.1",
+		},
+		releases: map[string]string{},
+		versions: map[string]string{},
+	}
+	strategy := PerformRelease(&EasyReleaseArgs{Branch: "master"}, &EasyReleaseContext{
+		Cfg:          cfg,
+		CommitParser: commitParser,
+		Api:          api,
+	})
+
+	if _, err := strategy.Execute(context.Background()); err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	if version := api.versions["api-2.0.0-rc.1"]; version != "2.0.0-rc.1" {
+		t.Errorf("expected the release api-2.0.0-rc.1 to be published with the version 2.0.0-rc.1, got: %v", api.versions)
+	}
+}
+
+func TestPerformReleaseChecksReleaseNotesBeforeTagging(t *testing.T) {
+	cfg := config.Default()
+	cfg.Updates = []config.Update{}
+	cfg.ReleaseNotes = []string{"json"}
+	commitParser, err := commits.NewParser(cfg)
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	api := &mockApi{
+		lastCommitSha:     "release-sha",
+		lastCommitMessage: "chore(release): 1.1.0",
+	}
+	strategy := PerformRelease(&EasyReleaseArgs{Branch: "master"}, &EasyReleaseContext{
+		Cfg:          cfg,
+		Git:          &mockGitCli{},
+		CommitParser: commitParser,
+		Api:          api,
+	})
+
+	result, err := strategy.Execute(context.Background())
+	if !errors.Is(err, config.ErrInvalidReleaseNotes) {
+		t.Fatalf("expected: %v, got: %v", config.ErrInvalidReleaseNotes, err)
+	}
+
+	if result != Error {
+		t.Errorf("expected: %s, got: %s", Error, result)
+	}
+
+	if len(api.tags) != 0 || len(api.commits) != 0 {
+		t.Errorf("expected nothing to be pushed, got tags: %v and commits: %v", api.tags, api.commits)
+	}
+}
+
+func TestPerformReleaseWritesReleaseNotes(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("pyproject.toml", []byte("[project]\nversion = \"1.1.0\"\n"), 0644); err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	cfg := config.Default()
+	cfg.Updates = []config.Update{{Kind: config.UpdateKindPython}}
+	cfg.ReleaseNotes = []string{config.ReleaseNotesJson}
+	commitParser, err := commits.NewParser(cfg)
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	sections, err := config.PivotSections(cfg)
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	changelogBuilder, err := changelog.NewBuilder(cfg, sections)
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	git := &mockGitCli{
+		tags: [][]string{{"1.0.0", "1.1.0-rc.1", "0.9.0"}},
+		log:  [][]string{{"chore(release): 1.1.0", "feat: [JIRA-1] a new endpoint"}},
+	}
+	strategy := PerformRelease(&EasyReleaseArgs{Branch: "master"}, &EasyReleaseContext{
+		Cfg:              cfg,
+		Git:              git,
+		CommitParser:     commitParser,
+		ChangelogBuilder: changelogBuilder,
+		Api: &mockApi{
+			lastCommitSha:     "release-sha",
+			lastCommitMessage: "chore(release): 1.1.0",
+		},
+	})
+
+	result, err := strategy.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	if result != Done {
+		t.Errorf("expected: %s, got: %s", Done, result)
+	}
+
+	if len(git.logRanges) != 1 || git.logRanges[0] != "1.0.0..release-sha" {
+		t.Errorf("expected the history since 1.0.0, got: %v", git.logRanges)
+	}
+
+	content, err := os.ReadFile("release-notes.json")
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	notes := changelog.ReleaseNotes{}
+	if err := json.Unmarshal(content, &notes); err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	if len(notes.Releases) != 1 || notes.Releases[0].Version != "1.1.0" || notes.Releases[0].PreviousVersion != "1.0.0" {
+		t.Fatalf("unexpected release notes: %s", content)
+	}
+
+	if files := notes.Releases[0].Files; len(files) != 2 || files[0] != "CHANGELOG.md" || files[1] != "pyproject.toml" {
+		t.Errorf("expected the changelog and the updated files, got: %v", files)
+	}
+
+	section := notes.Releases[0].Sections[0]
+	if section.Title != "Features" || len(section.Commits) != 1 || section.Commits[0].Links[0].Text != "JIRA-1" {
+		t.Errorf("unexpected release notes section: %+v", section)
+	}
+}
+
+func TestPerformReleaseMakesGradleSnapshot(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cfg := config.Default()
+	cfg.Updates = []config.Update{{FilePath: "gradle.properties", Kind: config.UpdateKindGradle}}
+	commitParser, err := commits.NewParser(cfg)
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	if err := os.WriteFile("gradle.properties", []byte("version=1.1.0\n"), 0644); err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	api := &mockApi{
+		lastCommitSha:     "release-sha",
+		lastCommitMessage: "chore(release): 1.1.0",
+	}
+	strategy := PerformRelease(&EasyReleaseArgs{Branch: "master"}, &EasyReleaseContext{
+		Cfg:          cfg,
+		CommitParser: commitParser,
+		Api:          api,
+	})
+
+	result, err := strategy.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	if result != Done {
+		t.Errorf("expected: %s, got: %s", Done, result)
+	}
+
+	if len(api.commits) != 1 || api.commits[0] != "chore(snapshot): 1.1.1-SNAPSHOT" {
+		t.Errorf("expected a snapshot commit, got: %v", api.commits)
+	}
+}
+
+func TestPerformReleaseSnapshotPushesSharedFileOnce(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cfg := config.Default()
+	cfg.Packages = []config.Package{
+		{Path: "packages/api", Updates: []config.Update{{FilePath: "../../gradle.properties", Kind: config.UpdateKindGradle}}},
+		{Path: "packages/web", Updates: []config.Update{{FilePath: "../../gradle.properties", Kind: config.UpdateKindGradle}}},
+	}
+	commitParser, err := commits.NewParser(cfg)
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	if err := os.WriteFile("gradle.properties", []byte("version=1.3.0\n"), 0644); err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	api := &mockApi{
+		lastCommitSha:     "release-sha",
+		lastCommitMessage: "chore(release): api@1.3.0, web@0.3.1 (#8)",
+	}
+	strategy := PerformRelease(&EasyReleaseArgs{Branch: "master"}, &EasyReleaseContext{
+		Cfg:          cfg,
+		CommitParser: commitParser,
+		Api:          api,
+	})
+
+	if _, err := strategy.Execute(context.Background()); err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	if len(api.commitChanges) != 1 {
+		t.Fatalf("expected a snapshot commit, got: %v", api.commits)
+	}
+
+	changes := api.commitChanges[0]
+	if len(changes) != 1 || changes[0].Path != "gradle.properties" || changes[0].Content != "version=0.3.2-SNAPSHOT\n" {
+		t.Errorf("expected gradle.properties once with the last snapshot version, got: %v", changes)
+	}
+}
+
+func TestPerformReleaseRejectsUnknownPackage(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cfg := config.Default()
+	cfg.Packages = []config.Package{{Path: "packages/api"}}
+	commitParser, err := commits.NewParser(cfg)
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	strategy := PerformRelease(&EasyReleaseArgs{Branch: "master"}, &EasyReleaseContext{
+		Cfg:          cfg,
+		CommitParser: commitParser,
+		Api: &mockApi{
+			lastCommitSha:     "release-sha",
+			lastCommitMessage: "chore(release): web@0.3.1",
+		},
+	})
+
+	result, err := strategy.Execute(context.Background())
+	if !errors.Is(err, ErrUnknownPackage) {
+		t.Errorf("expected: %v, got: %v", ErrUnknownPackage, err)
+	}
+
+	if result != Error {
+		t.Errorf("expected: %s, got: %s", Error, result)
+	}
+}
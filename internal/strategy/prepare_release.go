@@ -5,126 +5,329 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/rikotsev/easy-release/internal/changelog"
 	"github.com/rikotsev/easy-release/internal/commits"
+	"github.com/rikotsev/easy-release/internal/config"
 	"github.com/rikotsev/easy-release/internal/update"
 	"github.com/rikotsev/easy-release/internal/vcs"
+	"github.com/rikotsev/easy-release/internal/version"
+)
+
+const (
+	// packageNameSeparator joins the package name and version in release commits of monorepos - `api@1.2.0`.
+	packageNameSeparator = "@"
+	// releaseAsLabelPrefix marks a release PR label forcing the version - `release-as: 3.0.0` or `release-as: api@3.0.0`.
+	releaseAsLabelPrefix = "release-as:"
 )
 
 type PrepareReleaseImpl struct {
-	args             *EasyReleaseArgs
-	appCtx           *EasyReleaseContext
+	args       *EasyReleaseArgs
+	appCtx     *EasyReleaseContext
+	baseBranch string
+	channel    string
+	packages   []*packageRelease
+}
+
+// packageRelease holds the state of a single package while its release is being prepared.
+type packageRelease struct {
+	pkg              config.Package
+	tags             []string
 	startingSha      string
 	extractedCommits []commits.Commit
 	nextVersion      string
-	newChangelog     string
+	data             changelog.Changelog
+	description      string
 	remoteChanges    []vcs.RemoteChange
-	baseBranch       string
-	releaseBranch    string
-	releaseLastSha   string
+}
+
+// releasePullRequest is a release branch with the packages it releases.
+type releasePullRequest struct {
+	packages       []*packageRelease
+	releaseBranch  string
+	releaseLastSha string
+	id             int
+	updatedFiles   []update.UpdatedFile // the files updated by the packages so far - a file shared by packages is updated by all
 }
 
 func PrepareRelease(args *EasyReleaseArgs, applicationContext *EasyReleaseContext) Strategy {
 	return &PrepareReleaseImpl{
-		args:          args,
-		appCtx:        applicationContext,
-		remoteChanges: []vcs.RemoteChange{},
-		baseBranch:    args.Branch,
-		releaseBranch: fmt.Sprintf("%s%s", applicationContext.Cfg.ReleaseBranchPrefix, args.Branch),
+		args:       args,
+		appCtx:     applicationContext,
+		baseBranch: args.Branch,
 	}
 }
 
 func (strat *PrepareReleaseImpl) Execute(ctx context.Context) (StrategyResult, error) {
+	if err := config.Validate(strat.appCtx.Cfg); err != nil {
+		return Error, err
+	}
+
 	if err := strat.walkGitHistory(ctx); err != nil {
 		return NotApplicable, err
 	}
 
-	if strat.nextVersion == strat.startingSha {
+	if len(strat.packages) == 0 {
 		slog.Info("Nothing worth tracking has happened!")
 		return NotApplicable, nil
 	}
 
-	if err := strat.updateChangelog(); err != nil {
-		return Error, err
-	}
+	for _, pr := range strat.pullRequests() {
+		if err := strat.findThePR(ctx, pr); err != nil {
+			return Error, err
+		}
 
-	if err := strat.updatePathsWithNewVersion(); err != nil {
-		return Error, err
-	}
+		if err := strat.applyReleaseAsLabels(ctx, pr); err != nil {
+			return Error, err
+		}
 
-	if err := strat.keepReleaseBranchUpToDate(ctx); err != nil {
-		return Error, err
-	}
+		for _, release := range pr.packages {
+			if err := strat.updateChangelog(release); err != nil {
+				return Error, err
+			}
 
-	if err := strat.makeCommitWithReleaseChanges(ctx); err != nil {
-		return Error, err
+			if err := strat.updatePathsWithNewVersion(pr, release); err != nil {
+				return Error, err
+			}
+		}
+
+		if err := strat.keepReleaseBranchUpToDate(ctx, pr); err != nil {
+			return Error, err
+		}
+
+		if err := strat.makeCommitWithReleaseChanges(ctx, pr); err != nil {
+			return Error, err
+		}
+
+		if err := strat.makeOrUpdateThePR(ctx, pr); err != nil {
+			return Error, err
+		}
 	}
 
-	if err := strat.makeOrUpdateThePR(ctx); err != nil {
+	if err := strat.writeReleaseNotes(); err != nil {
 		return Error, err
 	}
 
 	return Done, nil
 }
 
+// walkGitHistory determines the next version of every package and keeps only the ones that have something to release.
 func (strat *PrepareReleaseImpl) walkGitHistory(ctx context.Context) error {
+	packages, err := config.ResolvePackages(strat.appCtx.Cfg)
+	if err != nil {
+		return fmt.Errorf("failed to resolve packages: %w", err)
+	}
+
 	tags, err := strat.appCtx.Git.Tags(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get tags: %w", err)
 	}
 
-	strat.startingSha = strat.appCtx.VersionManager.Current(tags)
-	logEntries, err := strat.appCtx.Git.Log(ctx, strat.startingSha)
+	strat.channel = PrereleaseChannel(strat.appCtx.Cfg, strat.args)
+
+	strat.packages = []*packageRelease{}
+	for _, pkg := range packages {
+		release := &packageRelease{
+			pkg:           pkg,
+			remoteChanges: []vcs.RemoteChange{},
+		}
+
+		release.tags = version.TagsWithPrefix(tags, pkg.TagPrefix)
+		release.startingSha = strat.appCtx.VersionManager.Current(release.tags)
+		startingRef := ""
+		if release.startingSha != "" {
+			startingRef = pkg.TagPrefix + release.startingSha
+		}
+
+		paths := []string{}
+		if pkg.Path != "" {
+			paths = append(paths, pkg.Path)
+		}
+
+		logEntries, err := strat.appCtx.Git.Log(ctx, startingRef, paths...)
+		if err != nil {
+			return fmt.Errorf("failed to get log entries: %w", err)
+		}
+
+		release.extractedCommits = strat.appCtx.CommitParser.Extract(ctx, logEntries)
+		release.nextVersion, err = strat.appCtx.VersionManager.Next(release.startingSha, release.extractedCommits)
+		if err != nil {
+			return fmt.Errorf("failed to determine next version: %w", err)
+		}
+
+		if release.nextVersion == release.startingSha {
+			slog.Info("nothing worth tracking has happened for package", "package", pkg.Name)
+			continue
+		}
+
+		if strat.channel != "" {
+			releasable, err := strat.changedSincePrerelease(ctx, release, paths)
+			if err != nil {
+				return err
+			}
+
+			if !releasable {
+				slog.Info("nothing worth tracking has happened since the last prerelease", "package", pkg.Name, "channel", strat.channel)
+				continue
+			}
+		}
+
+		if err := strat.applyChannel(release); err != nil {
+			return err
+		}
+
+		strat.packages = append(strat.packages, release)
+	}
+
+	return nil
+}
+
+// changedSincePrerelease reports whether there are tracked commits after the last prerelease of the channel.
+// The changelog of a prerelease covers everything since the last stable version, so this is what prevents an endless
+// chain of prereleases with the same content.
+func (strat *PrepareReleaseImpl) changedSincePrerelease(ctx context.Context, release *packageRelease, paths []string) (bool, error) {
+	latest := strat.appCtx.VersionManager.LatestPrerelease(release.tags, strat.channel)
+	if latest == "" {
+		return true, nil
+	}
+
+	logEntries, err := strat.appCtx.Git.Log(ctx, release.pkg.TagPrefix+latest, paths...)
 	if err != nil {
-		return fmt.Errorf("failed to get log entries: %w", err)
+		return false, fmt.Errorf("failed to get log entries since: %s with: %w", latest, err)
+	}
+
+	return strat.appCtx.VersionManager.Releasable(strat.appCtx.CommitParser.Extract(ctx, logEntries)), nil
+}
+
+// applyChannel turns the next stable version into the next prerelease when the branch releases on a channel.
+func (strat *PrepareReleaseImpl) applyChannel(release *packageRelease) error {
+	if strat.channel == "" {
+		return nil
 	}
 
-	strat.extractedCommits = strat.appCtx.CommitParser.Extract(ctx, logEntries)
-	strat.nextVersion, err = strat.appCtx.VersionManager.Next(strat.startingSha, strat.extractedCommits)
+	var err error
+	release.nextVersion, err = strat.appCtx.VersionManager.NextPrerelease(release.nextVersion, strat.channel, release.tags)
 	if err != nil {
-		return fmt.Errorf("failed to determine next version: %w", err)
+		return fmt.Errorf("failed to determine next prerelease version: %w", err)
 	}
 
 	return nil
 }
 
-func (strat *PrepareReleaseImpl) updateChangelog() error {
-	chnglog, err := strat.appCtx.ChangelogBuilder.Generate(strat.nextVersion, strat.extractedCommits, time.Now())
+func (strat *PrepareReleaseImpl) updateChangelog(release *packageRelease) error {
+	data, err := strat.appCtx.ChangelogBuilder.Data(release.nextVersion, release.startingSha, release.pkg.TagPrefix, release.extractedCommits, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to generate changelog: %w", err)
+	}
+
+	chnglog, err := strat.appCtx.ChangelogBuilder.Changelog(data)
 	if err != nil {
 		return fmt.Errorf("failed to generate changelog: %w", err)
 	}
 
-	currentChangelog, err := os.ReadFile(strat.appCtx.Cfg.ChangelogPath)
+	description, err := strat.appCtx.ChangelogBuilder.PullRequest(data)
 	if err != nil {
-		return fmt.Errorf("make sure a %s file exists. failed to read changelog: %w", strat.appCtx.Cfg.ChangelogPath, err)
+		return fmt.Errorf("failed to generate pull request description: %w", err)
 	}
 
-	strat.newChangelog = string(chnglog)
-	strat.remoteChanges = append(strat.remoteChanges, vcs.RemoteChange{
-		Path:    strat.appCtx.Cfg.ChangelogPath,
-		Content: string(append(chnglog[:], currentChangelog[:]...)),
+	currentChangelog, err := os.ReadFile(release.pkg.ChangelogPath)
+	if err != nil {
+		return fmt.Errorf("make sure a %s file exists. failed to read changelog: %w", release.pkg.ChangelogPath, err)
+	}
+
+	release.data = data
+	release.description = string(description)
+	release.remoteChanges = append(release.remoteChanges, vcs.RemoteChange{
+		Path:    release.pkg.ChangelogPath,
+		Content: string(changelog.Merge(currentChangelog, chnglog, release.nextVersion)),
 	})
 
 	return nil
 }
 
-func (strat *PrepareReleaseImpl) updatePathsWithNewVersion() error {
-	for idx, updCfg := range strat.appCtx.Cfg.Updates {
-		updatedFile, newContent, err := update.Execute(strat.nextVersion, updCfg)
-		if err != nil {
-			return fmt.Errorf("failed to perform update for %s [%d] with %w", updCfg.FilePath, idx, err)
-		}
-		strat.remoteChanges = append(strat.remoteChanges, vcs.RemoteChange{
-			Path:    updatedFile,
-			Content: string(newContent),
+func (strat *PrepareReleaseImpl) updatePathsWithNewVersion(pr *releasePullRequest, release *packageRelease) error {
+	updatedFiles, err := update.ExecuteOn(pr.updatedFiles, release.nextVersion, release.pkg.Updates)
+	if err != nil {
+		return fmt.Errorf("failed to perform updates with %w", err)
+	}
+
+	for _, updatedFile := range updatedFiles {
+		release.remoteChanges = append(release.remoteChanges, vcs.RemoteChange{
+			Path:    updatedFile.Path,
+			Content: string(updatedFile.Content),
 		})
+
+		replaced := false
+		for idx := range pr.updatedFiles {
+			if pr.updatedFiles[idx].Path == updatedFile.Path {
+				pr.updatedFiles[idx] = updatedFile
+				replaced = true
+			}
+		}
+		if !replaced {
+			pr.updatedFiles = append(pr.updatedFiles, updatedFile)
+		}
+	}
+
+	return nil
+}
+
+// writeReleaseNotes writes the notes of the proposed releases. The files are those changed in the release PR.
+func (strat *PrepareReleaseImpl) writeReleaseNotes() error {
+	if len(strat.appCtx.Cfg.ReleaseNotes) == 0 || strat.args.DryRun {
+		return nil
+	}
+
+	notes := changelog.ReleaseNotes{Releases: []changelog.PackageNotes{}}
+	for _, release := range strat.packages {
+		files := make([]string, 0, len(release.remoteChanges))
+		for _, change := range release.remoteChanges {
+			files = append(files, change.Path)
+		}
+
+		notes.Releases = append(notes.Releases,
+			changelog.Notes(release.pkg.Name, release.pkg.TagPrefix+release.nextVersion, release.data, files))
+	}
+
+	if err := writeReleaseNotes(strat.appCtx.Cfg, notes); err != nil {
+		return fmt.Errorf("failed to write release notes: %w", err)
 	}
 
 	return nil
 }
 
-func (strat *PrepareReleaseImpl) keepReleaseBranchUpToDate(ctx context.Context) error {
+// pullRequests groups the packages into a single combined release PR or one PR per package.
+func (strat *PrepareReleaseImpl) pullRequests() []*releasePullRequest {
+	releaseBranch := fmt.Sprintf("%s%s", strat.appCtx.Cfg.ReleaseBranchPrefix, strat.baseBranch)
+
+	if !strat.appCtx.Cfg.SeparatePullRequests {
+		return []*releasePullRequest{
+			{
+				packages:      strat.packages,
+				releaseBranch: releaseBranch,
+			},
+		}
+	}
+
+	result := make([]*releasePullRequest, 0, len(strat.packages))
+	for _, release := range strat.packages {
+		branch := releaseBranch
+		if release.pkg.Name != "" {
+			branch = fmt.Sprintf("%s--%s", releaseBranch, release.pkg.Name)
+		}
+
+		result = append(result, &releasePullRequest{
+			packages:      []*packageRelease{release},
+			releaseBranch: branch,
+		})
+	}
+
+	return result
+}
+
+func (strat *PrepareReleaseImpl) keepReleaseBranchUpToDate(ctx context.Context, pr *releasePullRequest) error {
 	baseLastSha, err := strat.appCtx.Api.GetLastRef(ctx, strat.baseBranch)
 	if err != nil {
 		return fmt.Errorf("could not get base branch: %s last sha with: %w", strat.baseBranch, err)
@@ -133,48 +336,112 @@ func (strat *PrepareReleaseImpl) keepReleaseBranchUpToDate(ctx context.Context)
 		return fmt.Errorf("base branch: %s should have commits. something is terribly wrong", strat.baseBranch)
 	}
 
-	releaseLastSha, err := strat.appCtx.Api.GetLastRef(ctx, strat.releaseBranch)
+	releaseLastSha, err := strat.appCtx.Api.GetLastRef(ctx, pr.releaseBranch)
 	if err != nil {
-		return fmt.Errorf("could not get release branch: %s last sha with: %w", strat.releaseBranch, err)
+		return fmt.Errorf("could not get release branch: %s last sha with: %w", pr.releaseBranch, err)
 	}
 
 	if releaseLastSha == "" {
 		releaseLastSha = "0000000000000000000000000000000000000000"
 	}
 
-	strat.releaseLastSha, err = strat.appCtx.Api.UpdateRef(ctx, strat.releaseBranch, baseLastSha, releaseLastSha)
+	pr.releaseLastSha, err = strat.appCtx.Api.UpdateRef(ctx, pr.releaseBranch, baseLastSha, releaseLastSha)
 	if err != nil {
 		return fmt.Errorf("failed to update release branch: %s with current sha: %s to new sha: %s with: %w",
-			strat.releaseBranch, releaseLastSha, baseLastSha, err)
+			pr.releaseBranch, releaseLastSha, baseLastSha, err)
 	}
 
 	return nil
 }
 
-func (strat *PrepareReleaseImpl) makeCommitWithReleaseChanges(ctx context.Context) error {
-	message := strat.releaseMessage()
+func (strat *PrepareReleaseImpl) makeCommitWithReleaseChanges(ctx context.Context, pr *releasePullRequest) error {
+	message := strat.releaseMessage(pr)
+
+	// a file changed by several packages is pushed once with the content after the last of them
+	changes := []vcs.RemoteChange{}
+	pathToIdx := map[string]int{}
+	for _, release := range pr.packages {
+		for _, change := range release.remoteChanges {
+			if idx, ok := pathToIdx[change.Path]; ok {
+				changes[idx] = change
+				continue
+			}
+
+			pathToIdx[change.Path] = len(changes)
+			changes = append(changes, change)
+		}
+	}
 
-	return strat.appCtx.Api.PushCommit(ctx, strat.releaseBranch, strat.releaseLastSha, message, strat.remoteChanges)
+	return strat.appCtx.Api.PushCommit(ctx, pr.releaseBranch, pr.releaseLastSha, message, changes)
 }
 
-func (strat *PrepareReleaseImpl) makeOrUpdateThePR(ctx context.Context) error {
-	prId, err := strat.appCtx.Api.GetPR(ctx, strat.baseBranch, strat.releaseBranch)
+func (strat *PrepareReleaseImpl) findThePR(ctx context.Context, pr *releasePullRequest) error {
+	var err error
+	pr.id, err = strat.appCtx.Api.GetPR(ctx, strat.baseBranch, pr.releaseBranch)
 	if err != nil {
 		return fmt.Errorf("could not get a pr: %w", err)
 	}
-	prContent := strat.newChangelog
+
+	return nil
+}
+
+// applyReleaseAsLabels overrides the versions with the `release-as: 3.0.0` labels of an existing release PR.
+// A label of a PR releasing several packages has to name the package - `release-as: api@3.0.0`.
+func (strat *PrepareReleaseImpl) applyReleaseAsLabels(ctx context.Context, pr *releasePullRequest) error {
+	reader, ok := strat.appCtx.Api.(vcs.LabelReader)
+	if !ok || pr.id == -1 {
+		return nil
+	}
+
+	labels, err := reader.GetPRLabels(ctx, pr.id)
+	if err != nil {
+		return fmt.Errorf("could not get the labels of pr: %d with: %w", pr.id, err)
+	}
+
+	for _, label := range labels {
+		if len(label) <= len(releaseAsLabelPrefix) || !strings.EqualFold(label[:len(releaseAsLabelPrefix)], releaseAsLabelPrefix) {
+			continue
+		}
+
+		requested := strings.TrimSpace(label[len(releaseAsLabelPrefix):])
+		name, requestedVersion, named := strings.Cut(requested, packageNameSeparator)
+		if !named {
+			requestedVersion = requested
+		}
+
+		for _, release := range pr.packages {
+			if (named && release.pkg.Name != name) || (!named && len(pr.packages) != 1) {
+				continue
+			}
+
+			release.nextVersion, err = strat.appCtx.VersionManager.ReleaseAs(release.startingSha, requestedVersion)
+			if err != nil {
+				return fmt.Errorf("pr label: %s cannot be applied with: %w", label, err)
+			}
+
+			if err := strat.applyChannel(release); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (strat *PrepareReleaseImpl) makeOrUpdateThePR(ctx context.Context, pr *releasePullRequest) error {
+	prContent := strat.releaseDescription(pr)
 
 	if len(prContent) > vcs.PullRequestDescriptionLimit {
 		prContent = prContent[:vcs.PullRequestDescriptionLimit]
 	}
 
-	if prId == -1 {
-		_, err := strat.appCtx.Api.CreatePR(ctx, strat.baseBranch, strat.releaseBranch, strat.releaseMessage(), prContent)
+	if pr.id == -1 {
+		_, err := strat.appCtx.Api.CreatePR(ctx, strat.baseBranch, pr.releaseBranch, strat.releaseMessage(pr), prContent)
 		if err != nil {
 			return fmt.Errorf("failed to create pr: %w", err)
 		}
 	} else {
-		_, err := strat.appCtx.Api.UpdatePR(ctx, prId, strat.releaseMessage(), prContent)
+		_, err := strat.appCtx.Api.UpdatePR(ctx, pr.id, strat.releaseMessage(pr), prContent)
 		if err != nil {
 			return fmt.Errorf("failed to edit pr: %w", err)
 		}
@@ -183,6 +450,30 @@ func (strat *PrepareReleaseImpl) makeOrUpdateThePR(ctx context.Context) error {
 	return nil
 }
 
-func (strat *PrepareReleaseImpl) releaseMessage() string {
-	return fmt.Sprintf("%s%s", strat.appCtx.Cfg.ReleaseCommitPrefix, strat.nextVersion)
+// releaseMessage is `<prefix>1.2.0` for a single project and `<prefix>api@1.2.0, web@0.3.0` for the packages of a monorepo.
+func (strat *PrepareReleaseImpl) releaseMessage(pr *releasePullRequest) string {
+	versions := make([]string, 0, len(pr.packages))
+	for _, release := range pr.packages {
+		if release.pkg.Name == "" {
+			versions = append(versions, release.nextVersion)
+			continue
+		}
+
+		versions = append(versions, release.pkg.Name+packageNameSeparator+release.nextVersion)
+	}
+
+	return fmt.Sprintf("%s%s", strat.appCtx.Cfg.ReleaseCommitPrefix, strings.Join(versions, ", "))
+}
+
+func (strat *PrepareReleaseImpl) releaseDescription(pr *releasePullRequest) string {
+	if len(pr.packages) == 1 {
+		return pr.packages[0].description
+	}
+
+	var description strings.Builder
+	for _, release := range pr.packages {
+		description.WriteString(fmt.Sprintf("# %s\n%s\n", release.pkg.Name, release.description))
+	}
+
+	return description.String()
 }
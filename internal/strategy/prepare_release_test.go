@@ -2,6 +2,7 @@ package strategy
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/rikotsev/easy-release/internal/changelog"
 	"github.com/rikotsev/easy-release/internal/commits"
 	"github.com/rikotsev/easy-release/internal/config"
@@ -65,6 +66,16 @@ func (s *PrepareReleaseTestSuite) SetupTest() {
 	s.Require().NoError(err)
 	s.changelogFile = file
 	s.appCtx.Cfg.ChangelogPath = file.Name()
+	s.appCtx.Cfg.Packages = nil
+	s.appCtx.Cfg.SeparatePullRequests = false
+	s.appCtx.Cfg.PrereleaseBranches = nil
+	s.appCtx.Cfg.ReleaseNotes = nil
+	s.args.Prerelease = ""
+	s.api.commits = nil
+	s.api.commitBranches = nil
+	s.api.commitChanges = nil
+	s.api.updateDescriptions = make([]string, 0)
+	s.git.logPaths = nil
 }
 
 func (s *PrepareReleaseTestSuite) TearDownTest() {
@@ -91,6 +102,221 @@ func (s *PrepareReleaseTestSuite) TestPullRequestDescriptionIsTruncated() {
 		"description", s.api.updateDescriptions[0])
 }
 
+func (s *PrepareReleaseTestSuite) givenMonorepo() {
+	s.T().Chdir(s.T().TempDir())
+	for _, dir := range []string{"packages/api", "packages/web"} {
+		s.Require().NoError(os.MkdirAll(dir, 0755))
+		s.Require().NoError(os.WriteFile(dir+"/CHANGELOG.md", []byte(""), 0644))
+	}
+	s.appCtx.Cfg.Packages = []config.Package{
+		{Path: "packages/api"},
+		{Path: "packages/web", TagPrefix: "web/v"},
+	}
+	s.git.tags = append(s.git.tags, []string{"1.0.0", "api-1.2.0", "web/v0.3.0"})
+	s.git.log = append(s.git.log,
+		[]string{"feat: [JIRA-1] a new endpoint"},
+		[]string{"fix: [JIRA-2] a button"},
+	)
+}
+
+func (s *PrepareReleaseTestSuite) TestMonorepoPackagesAreReleasedInOnePullRequest() {
+	s.givenMonorepo()
+	s.api.refs = append(s.api.refs, "master-sha", "release-sha")
+
+	res, err := PrepareRelease(s.args, s.appCtx).Execute(s.ctx)
+
+	s.Require().NoError(err)
+	s.Require().Equal(Done, res)
+	s.Equal([][]string{{"packages/api"}, {"packages/web"}}, s.git.logPaths)
+	s.Equal([]string{"chore(release): api@1.3.0, web@0.3.1"}, s.api.commits)
+	s.Equal([]string{"easy-release--master"}, s.api.commitBranches)
+	s.Require().Len(s.api.updateDescriptions, 1)
+	s.Contains(s.api.updateDescriptions[0], "# api\n")
+	s.Contains(s.api.updateDescriptions[0], "# web\n")
+}
+
+func (s *PrepareReleaseTestSuite) TestMonorepoPackagesShareTheUpdatedFiles() {
+	s.givenMonorepo()
+	s.Require().NoError(os.WriteFile("package.json", []byte(`{"workspaces": ["packages/*"]}`), 0644))
+	s.Require().NoError(os.WriteFile("packages/api/package.json", []byte(`{"name": "api", "version": "1.2.0"}`), 0644))
+	s.Require().NoError(os.WriteFile("packages/web/package.json",
+		[]byte(`{"name": "web", "version": "0.3.0", "dependencies": {"api": "^1.2.0"}}`), 0644))
+	s.Require().NoError(os.WriteFile("package-lock.json", []byte(`{"packages": {
+  "packages/api": {"version": "1.2.0"},
+  "packages/web": {"version": "0.3.0", "dependencies": {"api": "^1.2.0"}}
+}}`), 0644))
+	for idx := range s.appCtx.Cfg.Packages {
+		s.appCtx.Cfg.Packages[idx].Updates = []config.Update{{FilePath: "package.json", Kind: config.UpdateKindPackageJson}}
+	}
+	s.api.refs = append(s.api.refs, "master-sha", "release-sha")
+
+	res, err := PrepareRelease(s.args, s.appCtx).Execute(s.ctx)
+
+	s.Require().NoError(err)
+	s.Require().Equal(Done, res)
+	s.Require().Len(s.api.commitChanges, 1)
+	changes := map[string]string{}
+	for _, change := range s.api.commitChanges[0] {
+		s.NotContains(changes, change.Path, "every file is pushed once")
+		changes[change.Path] = change.Content
+	}
+	s.Equal(`{"name": "web", "version": "0.3.1", "dependencies": {"api": "^1.3.0"}}`, changes["packages/web/package.json"])
+	s.Equal(`{"packages": {
+  "packages/api": {"version": "1.3.0"},
+  "packages/web": {"version": "0.3.1", "dependencies": {"api": "^1.3.0"}}
+}}`, changes["package-lock.json"])
+}
+
+func (s *PrepareReleaseTestSuite) TestMonorepoPackagesAreReleasedInSeparatePullRequests() {
+	s.givenMonorepo()
+	s.appCtx.Cfg.SeparatePullRequests = true
+	s.api.refs = append(s.api.refs, "master-sha", "api-release-sha", "master-sha", "web-release-sha")
+
+	res, err := PrepareRelease(s.args, s.appCtx).Execute(s.ctx)
+
+	s.Require().NoError(err)
+	s.Require().Equal(Done, res)
+	s.Equal([]string{"chore(release): api@1.3.0", "chore(release): web@0.3.1"}, s.api.commits)
+	s.Equal([]string{"easy-release--master--api", "easy-release--master--web"}, s.api.commitBranches)
+}
+
+func (s *PrepareReleaseTestSuite) TestMonorepoPackagesWithoutChangesAreSkipped() {
+	s.givenMonorepo()
+	s.git.log[1] = []string{"chore: [JIRA-3] tidy up"}
+	s.api.refs = append(s.api.refs, "master-sha", "release-sha")
+
+	res, err := PrepareRelease(s.args, s.appCtx).Execute(s.ctx)
+
+	s.Require().NoError(err)
+	s.Require().Equal(Done, res)
+	s.Equal([]string{"chore(release): api@1.3.0"}, s.api.commits)
+	s.NotContains(s.api.updateDescriptions[0], "# api")
+}
+
+func (s *PrepareReleaseTestSuite) TestPrereleaseContinuesTheChannel() {
+	s.appCtx.Cfg.PrereleaseBranches = map[string]string{"master": "rc"}
+	s.git.tags = append(s.git.tags, []string{"1.0.0", "2.0.0-rc.1", "1.0.0-beta.1"})
+	s.git.log = append(s.git.log,
+		[]string{"feat!: [JIRA-1] a breaking change", "fix: [JIRA-2] a bug"},
+		[]string{"fix: [JIRA-2] a bug"},
+	)
+	s.api.refs = append(s.api.refs, "master-sha", "release-sha")
+
+	res, err := PrepareRelease(s.args, s.appCtx).Execute(s.ctx)
+
+	s.Require().NoError(err)
+	s.Require().Equal(Done, res)
+	s.Equal([]string{"chore(release): 2.0.0-rc.2"}, s.api.commits)
+	s.Contains(s.api.updateDescriptions[0], "a breaking change", "the changelog covers everything since the last stable version")
+}
+
+func (s *PrepareReleaseTestSuite) TestPrereleaseIsSkippedWithoutChangesSinceTheLastOne() {
+	s.args.Prerelease = "rc"
+	s.git.tags = append(s.git.tags, []string{"1.0.0", "2.0.0-rc.1"})
+	s.git.log = append(s.git.log,
+		[]string{"chore(release): 2.0.0-rc.1", "feat!: [JIRA-1] a breaking change"},
+		[]string{"chore(release): 2.0.0-rc.1"},
+	)
+
+	res, err := PrepareRelease(s.args, s.appCtx).Execute(s.ctx)
+
+	s.Require().NoError(err)
+	s.Require().Equal(NotApplicable, res)
+	s.Empty(s.api.commits)
+}
+
+func (s *PrepareReleaseTestSuite) TestStableReleaseGraduatesThePrerelease() {
+	s.git.tags = append(s.git.tags, []string{"1.0.0", "2.0.0-rc.1", "2.0.0-rc.2"})
+	s.git.log = append(s.git.log, []string{"chore(release): 2.0.0-rc.2", "feat!: [JIRA-1] a breaking change"})
+	s.api.refs = append(s.api.refs, "master-sha", "release-sha")
+
+	res, err := PrepareRelease(s.args, s.appCtx).Execute(s.ctx)
+
+	s.Require().NoError(err)
+	s.Require().Equal(Done, res)
+	s.Equal([]string{"chore(release): 2.0.0"}, s.api.commits)
+}
+
+func (s *PrepareReleaseTestSuite) TestReleaseAsFooterOverridesTheVersion() {
+	s.git.tags = append(s.git.tags, []string{"1.0.0"})
+	s.git.log = append(s.git.log, []string{"fix: [JIRA-1] a bug", "chore: the next major\n\nRelease-As: 3.0.0"})
+	s.api.refs = append(s.api.refs, "master-sha", "release-sha")
+
+	res, err := PrepareRelease(s.args, s.appCtx).Execute(s.ctx)
+
+	s.Require().NoError(err)
+	s.Require().Equal(Done, res)
+	s.Equal([]string{"chore(release): 3.0.0"}, s.api.commits)
+	s.Contains(s.api.updateDescriptions[0], "## 3.0.0")
+}
+
+func (s *PrepareReleaseTestSuite) TestReleaseAsLabelOverridesTheVersion() {
+	s.git.tags = append(s.git.tags, []string{"1.0.0"})
+	s.git.log = append(s.git.log, []string{"fix: [JIRA-1] a bug"})
+	s.api.refs = append(s.api.refs, "master-sha", "release-sha")
+	s.appCtx.Api = &mockLabelApi{mockApi: s.api, labels: []string{"bug", "Release-As: 2.5.0"}}
+	defer func() { s.appCtx.Api = s.api }()
+
+	res, err := PrepareRelease(s.args, s.appCtx).Execute(s.ctx)
+
+	s.Require().NoError(err)
+	s.Require().Equal(Done, res)
+	s.Equal([]string{"chore(release): 2.5.0"}, s.api.commits)
+	s.Contains(s.api.updateDescriptions[0], "## 2.5.0")
+}
+
+func (s *PrepareReleaseTestSuite) TestReleaseAsLabelNamesThePackage() {
+	s.givenMonorepo()
+	s.api.refs = append(s.api.refs, "master-sha", "release-sha")
+	s.appCtx.Api = &mockLabelApi{mockApi: s.api, labels: []string{"release-as: web@1.0.0"}}
+	defer func() { s.appCtx.Api = s.api }()
+
+	res, err := PrepareRelease(s.args, s.appCtx).Execute(s.ctx)
+
+	s.Require().NoError(err)
+	s.Require().Equal(Done, res)
+	s.Equal([]string{"chore(release): api@1.3.0, web@1.0.0"}, s.api.commits)
+}
+
+func (s *PrepareReleaseTestSuite) TestReleaseAsHasToBeGreaterThanTheCurrentVersion() {
+	s.git.tags = append(s.git.tags, []string{"1.0.0"})
+	s.git.log = append(s.git.log, []string{"fix: [JIRA-1] a bug"})
+	s.appCtx.Api = &mockLabelApi{mockApi: s.api, labels: []string{"release-as: 1.0.0"}}
+	defer func() { s.appCtx.Api = s.api }()
+
+	res, err := PrepareRelease(s.args, s.appCtx).Execute(s.ctx)
+
+	s.Require().ErrorIs(err, version.ErrInvalidReleaseAs)
+	s.Require().Equal(Error, res)
+	s.Empty(s.api.commits)
+}
+
+func (s *PrepareReleaseTestSuite) TestReleaseNotesAreWritten() {
+	s.T().Chdir(s.T().TempDir())
+	s.appCtx.Cfg.ReleaseNotes = []string{config.ReleaseNotesJson, config.ReleaseNotesYaml}
+	s.git.tags = append(s.git.tags, []string{"1.0.0"})
+	s.git.log = append(s.git.log, []string{"feat(api): [JIRA-1] a new endpoint", "fix: [JIRA-2] a bug"})
+	s.api.refs = append(s.api.refs, "master-sha", "release-sha")
+
+	res, err := PrepareRelease(s.args, s.appCtx).Execute(s.ctx)
+
+	s.Require().NoError(err)
+	s.Require().Equal(Done, res)
+
+	content, err := os.ReadFile("release-notes.json")
+	s.Require().NoError(err)
+	notes := changelog.ReleaseNotes{}
+	s.Require().NoError(json.Unmarshal(content, &notes))
+	s.Require().Len(notes.Releases, 1)
+	s.Equal("1.1.0", notes.Releases[0].Tag)
+	s.Equal("1.0.0", notes.Releases[0].PreviousVersion)
+	s.Equal([]string{s.appCtx.Cfg.ChangelogPath}, notes.Releases[0].Files)
+	s.Require().Len(notes.Releases[0].Sections, 2)
+	s.Equal("api", notes.Releases[0].Sections[0].Commits[0].Scope)
+
+	s.FileExists("release-notes.yaml")
+}
+
 func TestPrepareReleaseTestSuite(t *testing.T) {
 	suite.Run(t, new(PrepareReleaseTestSuite))
 }
@@ -0,0 +1,147 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/rikotsev/easy-release/internal/changelog"
+	"github.com/rikotsev/easy-release/internal/config"
+	"github.com/rikotsev/easy-release/internal/version"
+)
+
+// RebuildChangelogImpl regenerates the changelog of every package from the history between its stable version tags.
+type RebuildChangelogImpl struct {
+	args   *ChangelogArgs
+	appCtx *EasyReleaseContext
+	output io.Writer
+}
+
+func RebuildChangelog(args *ChangelogArgs, applicationContext *EasyReleaseContext) Strategy {
+	return &RebuildChangelogImpl{
+		args:   args,
+		appCtx: applicationContext,
+		output: os.Stdout,
+	}
+}
+
+func (strat *RebuildChangelogImpl) Execute(ctx context.Context) (StrategyResult, error) {
+	packages, err := config.ResolvePackages(strat.appCtx.Cfg)
+	if err != nil {
+		return Error, fmt.Errorf("failed to resolve packages: %w", err)
+	}
+
+	tags, err := strat.appCtx.Git.Tags(ctx)
+	if err != nil {
+		return Error, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	tagDates, err := strat.appCtx.Git.TagDates(ctx)
+	if err != nil {
+		return Error, fmt.Errorf("failed to get tag dates: %w", err)
+	}
+
+	result := NotApplicable
+	for _, pkg := range packages {
+		rebuilt, err := strat.rebuild(ctx, pkg, tags, tagDates)
+		if err != nil {
+			return Error, err
+		}
+
+		if rebuilt {
+			result = Done
+		}
+	}
+
+	return result, nil
+}
+
+func (strat *RebuildChangelogImpl) rebuild(ctx context.Context, pkg config.Package, tags []string, tagDates map[string]time.Time) (bool, error) {
+	versions := stableVersions(version.TagsWithPrefix(tags, pkg.TagPrefix))
+	if len(versions) == 0 {
+		slog.Info("there are no version tags to rebuild the changelog from", "package", pkg.Name)
+		return false, nil
+	}
+
+	paths := []string{}
+	if pkg.Path != "" {
+		paths = append(paths, pkg.Path)
+	}
+
+	current, err := os.ReadFile(pkg.ChangelogPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("failed to read changelog: %s with: %w", pkg.ChangelogPath, err)
+	}
+	// only what is above the entries is kept, the entries themselves are all regenerated
+	content := changelog.Header(current)
+
+	// the entries are merged from the oldest, so every next one lands on top of the previous
+	previousVersion := ""
+	for _, released := range versions {
+		previousRef := ""
+		if previousVersion != "" {
+			previousRef = pkg.TagPrefix + previousVersion
+		}
+
+		tag := pkg.TagPrefix + released
+		logEntries, err := strat.appCtx.Git.LogBetween(ctx, previousRef, tag, paths...)
+		if err != nil {
+			return false, fmt.Errorf("failed to get log entries of: %s with: %w", tag, err)
+		}
+
+		extractedCommits := strat.appCtx.CommitParser.Extract(ctx, logEntries)
+		data, err := strat.appCtx.ChangelogBuilder.Data(released, previousVersion, pkg.TagPrefix, extractedCommits, tagDates[tag])
+		if err != nil {
+			return false, fmt.Errorf("failed to generate changelog of: %s with: %w", tag, err)
+		}
+
+		entry, err := strat.appCtx.ChangelogBuilder.Changelog(data)
+		if err != nil {
+			return false, fmt.Errorf("failed to generate changelog of: %s with: %w", tag, err)
+		}
+
+		content = changelog.Merge(content, entry, released)
+		previousVersion = released
+	}
+
+	if strat.args.DryRun {
+		_, err := fmt.Fprintf(strat.output, "%s:\n%s\n", pkg.ChangelogPath, content)
+		return true, err
+	}
+
+	if err := os.WriteFile(pkg.ChangelogPath, content, 0644); err != nil {
+		return false, fmt.Errorf("failed to write changelog: %s with: %w", pkg.ChangelogPath, err)
+	}
+
+	slog.Info("the changelog was rebuilt", "path", pkg.ChangelogPath, "versions", len(versions))
+
+	return true, nil
+}
+
+// stableVersions returns the strict semantic versions without prereleases in ascending order.
+func stableVersions(tags []string) []string {
+	semVersions := []*semver.Version{}
+	for _, tag := range tags {
+		sv, err := semver.StrictNewVersion(tag)
+		if err != nil || sv.Prerelease() != "" {
+			continue
+		}
+
+		semVersions = append(semVersions, sv)
+	}
+
+	sort.Sort(semver.Collection(semVersions))
+
+	result := make([]string, 0, len(semVersions))
+	for _, sv := range semVersions {
+		result = append(result, sv.String())
+	}
+
+	return result
+}
@@ -0,0 +1,26 @@
+package strategy
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rikotsev/easy-release/internal/changelog"
+	"github.com/rikotsev/easy-release/internal/config"
+)
+
+// writeReleaseNotes writes the release notes in every configured format next to the version file.
+func writeReleaseNotes(cfg *config.Config, notes changelog.ReleaseNotes) error {
+	for _, format := range cfg.ReleaseNotes {
+		content, err := notes.Encode(format)
+		if err != nil {
+			return err
+		}
+
+		fileName := changelog.NotesFileName(format)
+		if err := os.WriteFile(fileName, content, 0644); err != nil {
+			return fmt.Errorf("failed to write release notes: %s with: %w", fileName, err)
+		}
+	}
+
+	return nil
+}
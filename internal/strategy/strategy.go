@@ -5,6 +5,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"log/slog"
 
 	"github.com/rikotsev/easy-release/internal/changelog"
 	"github.com/rikotsev/easy-release/internal/cli"
@@ -25,6 +26,12 @@ const (
 	NotApplicable StrategyResult = "NotApplicable"
 	AzureDevops   VcsPlatform    = "azuredevops"
 	Github        VcsPlatform    = "github"
+	Gitlab        VcsPlatform    = "gitlab"
+	Bitbucket     VcsPlatform    = "bitbucket"
+	Gitea         VcsPlatform    = "gitea"
+	Local         VcsPlatform    = "local"
+	DryRunText    string         = "text"
+	DryRunJson    string         = "json"
 )
 
 type Strategy interface {
@@ -39,43 +46,156 @@ type EasyReleaseContext struct {
 	CommitParser        *commits.CommitParser
 	ChangelogBuilder    *changelog.ChangelogBuilder
 	Api                 vcs.Api
+	Recorder            *vcs.RecordingApi // set only on a dry run
 }
 
 type EasyReleaseArgs struct {
-	Vcs     VcsPlatform
-	Token   string
-	Org     string
-	Project string
-	Repo    string
-	Branch  string
+	Vcs          VcsPlatform
+	Token        string
+	Org          string
+	Project      string
+	Repo         string
+	Branch       string
+	BaseUrl      string
+	DryRun       bool
+	DryRunOutput string
+	Prerelease   string
 }
 
 func LoadEasyReleaseArgs() (*EasyReleaseArgs, error) {
-	vcsPlatform := flag.String("vcs", string(AzureDevops), "")
+	vcsPlatform := flag.String("vcs", string(AzureDevops), "The VCS platform - azuredevops, github, gitlab, bitbucket, gitea (also for Forgejo) or local (plain git)")
 	token := flag.String("token", "", "Access token to authenticate to the API")
-	org := flag.String("org", "", "Azure DevOps Organization Identifier / Empty for Github")
-	project := flag.String("project", "", "Azure DevOps Project Identifier / Github Owner")
+	org := flag.String("org", "", "Azure DevOps Organization Identifier / Empty for the other platforms")
+	project := flag.String("project", "", "Azure DevOps Project Identifier / Github Owner / Gitlab Namespace / Bitbucket Workspace or Project Key / Gitea Owner")
 	repo := flag.String("repo", "", "The Repository Name")
 	branch := flag.String("branch", "", "The branch used for versioning")
+	dryRun := flag.Bool("dry-run", false, "Perform only the read calls to the VCS and print the changes that would have been made")
+	dryRunOutput := flag.String("dry-run-output", DryRunText, "The format of the dry run plan - text or json")
+	prerelease := flag.String("prerelease", "", "The prerelease channel (e.g. alpha, beta, rc). Overrides the prereleaseBranches config for the branch")
+	baseUrl := flag.String("url", "", "The base URL of a self-hosted VCS instance (e.g. https://gitlab.example.com). For Bitbucket it selects Data Center over Cloud. For local it is the git remote")
 
 	flag.Parse()
 
-	if *token == "" || (*org == "" && *vcsPlatform == string(AzureDevops)) || *project == "" || *repo == "" || *branch == "" {
+	isLocal := *vcsPlatform == string(Local)
+	if (*token == "" && !isLocal) || (*org == "" && *vcsPlatform == string(AzureDevops)) ||
+		(*project == "" && !isLocal) || (*repo == "" && !isLocal) || *branch == "" {
 		flag.PrintDefaults()
 		return nil, errors.New("all arguments are required")
 	}
 
+	if *dryRunOutput != DryRunText && *dryRunOutput != DryRunJson {
+		flag.PrintDefaults()
+		return nil, fmt.Errorf("unrecognized dry run output: %s", *dryRunOutput)
+	}
+
 	return &EasyReleaseArgs{
+		Vcs:          VcsPlatform(*vcsPlatform),
+		Token:        *token,
+		Org:          *org,
+		Project:      *project,
+		Repo:         *repo,
+		Branch:       *branch,
+		BaseUrl:      *baseUrl,
+		DryRun:       *dryRun,
+		DryRunOutput: *dryRunOutput,
+		Prerelease:   *prerelease,
+	}, nil
+}
+
+// ChangelogArgs are the arguments of the `changelog` command. The platform is optional - it is only used for the links.
+type ChangelogArgs struct {
+	Rebuild bool
+	DryRun  bool
+	Vcs     VcsPlatform
+	Org     string
+	Project string
+	Repo    string
+}
+
+func LoadChangelogArgs(arguments []string) (*ChangelogArgs, error) {
+	flags := flag.NewFlagSet("changelog", flag.ContinueOnError)
+	rebuild := flags.Bool("rebuild", false, "Regenerate the changelog from the history between the semantic version tags")
+	dryRun := flags.Bool("dry-run", false, "Print the changelog instead of writing it")
+	vcsPlatform := flags.String("vcs", "", "The VCS platform the compare and commit links point to - azuredevops or github. No token is needed")
+	org := flags.String("org", "", "Azure DevOps Organization Identifier / Empty for the other platforms")
+	project := flags.String("project", "", "Azure DevOps Project Identifier / Github Owner")
+	repo := flags.String("repo", "", "The Repository Name")
+
+	if err := flags.Parse(arguments); err != nil {
+		return nil, err
+	}
+
+	if !*rebuild {
+		flags.PrintDefaults()
+		return nil, errors.New("nothing to do. use -rebuild to regenerate the changelog")
+	}
+
+	if *vcsPlatform != "" && (*project == "" || *repo == "" || (*org == "" && *vcsPlatform == string(AzureDevops))) {
+		flags.PrintDefaults()
+		return nil, errors.New("the links of the platform need the project and the repo (and the org for azuredevops)")
+	}
+
+	return &ChangelogArgs{
+		Rebuild: *rebuild,
+		DryRun:  *dryRun,
 		Vcs:     VcsPlatform(*vcsPlatform),
-		Token:   *token,
 		Org:     *org,
 		Project: *project,
 		Repo:    *repo,
-		Branch:  *branch,
 	}, nil
 }
 
 func CreateEasyReleaseContext(args *EasyReleaseArgs) (*EasyReleaseContext, error) {
+	result, err := CreateLocalContext()
+	if err != nil {
+		return nil, err
+	}
+
+	result.Api, err = CreateApi(result.Cfg, args)
+	if err != nil {
+		return nil, fmt.Errorf("could not instantiate devops api client: %w", err)
+	}
+
+	if provider, ok := result.Api.(vcs.LinkProvider); ok {
+		links := provider.WebLinks()
+		result.ChangelogBuilder.UseWebLinks(links.Compare, links.Commit)
+	}
+
+	if args.DryRun {
+		result.Recorder = vcs.NewRecording(result.Api)
+		result.Api = result.Recorder
+	}
+
+	return result, nil
+}
+
+// CreateChangelogContext creates the local context of the `changelog` command with the links of the platform in the
+// args, so a rebuilt changelog links the same way as the entries added by a release. The links are derived without
+// calling the platform.
+func CreateChangelogContext(args *ChangelogArgs) (*EasyReleaseContext, error) {
+	result, err := CreateLocalContext()
+	if err != nil {
+		return nil, err
+	}
+
+	links := vcs.WebLinks{}
+	switch args.Vcs {
+	case AzureDevops:
+		links = vcs.AzureDevopsWebLinks(args.Org, args.Project, args.Repo)
+	case Github:
+		links = vcs.GithubWebLinks(args.Project, args.Repo)
+	}
+	result.ChangelogBuilder.UseWebLinks(links.Compare, links.Commit)
+
+	if (result.Cfg.CompareUrl == "" && links.Compare == "") || (result.Cfg.CommitUrl == "" && links.Commit == "") {
+		slog.Warn("the changelog will have no compare or commit links. pass -vcs, -project and -repo or set compareUrl and commitUrl in the config")
+	}
+
+	return result, nil
+}
+
+// CreateLocalContext creates everything that works with the local repository only - there is no Api.
+func CreateLocalContext() (*EasyReleaseContext, error) {
 	result := EasyReleaseContext{}
 	var err error
 
@@ -106,8 +226,23 @@ func CreateEasyReleaseContext(args *EasyReleaseArgs) (*EasyReleaseContext, error
 		return nil, fmt.Errorf("could not instantiate changelog builder: %w", err)
 	}
 
-	if args.Vcs == AzureDevops {
-		result.Api, err = vcs.NewAzureDevops(result.Cfg,
+	return &result, nil
+}
+
+// PrereleaseChannel is the channel the branch releases on. An empty channel means stable releases.
+func PrereleaseChannel(cfg *config.Config, args *EasyReleaseArgs) string {
+	if args.Prerelease != "" {
+		return args.Prerelease
+	}
+
+	return cfg.PrereleaseBranches[args.Branch]
+}
+
+// CreateApi instantiates the vcs.Api implementation for the platform selected in the args.
+func CreateApi(cfg *config.Config, args *EasyReleaseArgs) (vcs.Api, error) {
+	switch args.Vcs {
+	case AzureDevops:
+		return vcs.NewAzureDevops(cfg,
 			vcs.ApiOpts{
 				Token:   args.Token,
 				Org:     args.Org,
@@ -115,21 +250,48 @@ func CreateEasyReleaseContext(args *EasyReleaseArgs) (*EasyReleaseContext, error
 				Repo:    args.Repo,
 				Branch:  args.Branch,
 			})
-	} else if args.Vcs == Github {
-		result.Api, err = vcs.NewGithub(result.Cfg,
+	case Github:
+		return vcs.NewGithub(cfg,
 			vcs.ApiOpts{
 				Token:   args.Token,
 				Project: args.Project,
 				Repo:    args.Repo,
 				Branch:  args.Branch,
 			})
-	} else {
-		return nil, fmt.Errorf("unrecognized vcs platform")
-	}
-
-	if err != nil {
-		return nil, fmt.Errorf("could not instantiate devops api client: %w", err)
+	case Gitlab:
+		return vcs.NewGitlab(cfg,
+			vcs.ApiOpts{
+				Token:   args.Token,
+				Project: args.Project,
+				Repo:    args.Repo,
+				Branch:  args.Branch,
+				BaseUrl: args.BaseUrl,
+			})
+	case Bitbucket:
+		return vcs.NewBitbucket(cfg,
+			vcs.ApiOpts{
+				Token:   args.Token,
+				Project: args.Project,
+				Repo:    args.Repo,
+				Branch:  args.Branch,
+				BaseUrl: args.BaseUrl,
+			})
+	case Gitea:
+		return vcs.NewGitea(cfg,
+			vcs.ApiOpts{
+				Token:   args.Token,
+				Project: args.Project,
+				Repo:    args.Repo,
+				Branch:  args.Branch,
+				BaseUrl: args.BaseUrl,
+			})
+	case Local:
+		return vcs.NewLocal(cfg,
+			vcs.ApiOpts{
+				Branch:  args.Branch,
+				BaseUrl: args.BaseUrl,
+			})
 	}
 
-	return &result, nil
+	return nil, fmt.Errorf("unrecognized vcs platform: %s", args.Vcs)
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/rikotsev/easy-release/internal/vcs"
 )
@@ -14,6 +16,12 @@ type mockApi struct {
 	refs               []string
 	createDescriptions []string
 	updateDescriptions []string
+	lastCommitSha      string
+	lastCommitMessage  string
+	tags               []string
+	commits            []string
+	commitBranches     []string
+	commitChanges      [][]vcs.RemoteChange
 }
 
 func (m *mockApi) GetLastRef(ctx context.Context, branch string) (string, error) {
@@ -33,7 +41,10 @@ func (m *mockApi) UpdateRef(ctx context.Context, branch string, newSha string, o
 }
 
 func (m *mockApi) PushCommit(ctx context.Context, branch string, lastSha string, message string, changes []vcs.RemoteChange) error {
-	//do nothing
+	m.commits = append(m.commits, message)
+	m.commitBranches = append(m.commitBranches, branch)
+	m.commitChanges = append(m.commitChanges, changes)
+
 	return nil
 }
 
@@ -55,13 +66,13 @@ func (m *mockApi) UpdatePR(ctx context.Context, prId int, title string, descript
 }
 
 func (m *mockApi) GetLastCommitMessage(ctx context.Context, branch string) (string, string, error) {
-	//TODO implement me
-	panic("implement me")
+	return m.lastCommitSha, m.lastCommitMessage, nil
 }
 
 func (m *mockApi) CreateAnnotatedTag(ctx context.Context, sha string, version string) error {
-	//TODO implement me
-	panic("implement me")
+	m.tags = append(m.tags, version)
+
+	return nil
 }
 
 func (m *mockApi) GetPRTitle(ctx context.Context, prId int) (string, error) {
@@ -69,9 +80,36 @@ func (m *mockApi) GetPRTitle(ctx context.Context, prId int) (string, error) {
 	panic("implement me")
 }
 
+type mockReleaseApi struct {
+	*mockApi
+	releases map[string]string
+	versions map[string]string
+}
+
+func (m *mockReleaseApi) CreateRelease(ctx context.Context, tag string, version string, notes string) error {
+	m.releases[tag] = notes
+	if m.versions != nil {
+		m.versions[tag] = version
+	}
+
+	return nil
+}
+
+type mockLabelApi struct {
+	*mockApi
+	labels []string
+}
+
+func (m *mockLabelApi) GetPRLabels(ctx context.Context, prId int) ([]string, error) {
+	return m.labels, nil
+}
+
 type mockGitCli struct {
-	tags [][]string
-	log  [][]string
+	tags      [][]string
+	tagDates  map[string]time.Time
+	log       [][]string
+	logPaths  [][]string
+	logRanges []string
 }
 
 func (git *mockGitCli) Tags(ctx context.Context) ([]string, error) {
@@ -84,7 +122,8 @@ func (git *mockGitCli) Tags(ctx context.Context) ([]string, error) {
 	return nil, noMoreStubs
 }
 
-func (git *mockGitCli) Log(ctx context.Context, startingSha string) ([]string, error) {
+func (git *mockGitCli) Log(ctx context.Context, startingSha string, paths ...string) ([]string, error) {
+	git.logPaths = append(git.logPaths, paths)
 	if len(git.log) > 0 {
 		result, whatsLeft := git.log[0], git.log[1:]
 		git.log = whatsLeft
@@ -95,6 +134,16 @@ func (git *mockGitCli) Log(ctx context.Context, startingSha string) ([]string, e
 	return nil, noMoreStubs
 }
 
+func (git *mockGitCli) TagDates(ctx context.Context) (map[string]time.Time, error) {
+	return git.tagDates, nil
+}
+
+func (git *mockGitCli) LogBetween(ctx context.Context, fromRef string, toRef string, paths ...string) ([]string, error) {
+	git.logRanges = append(git.logRanges, fromRef+".."+toRef)
+
+	return git.Log(ctx, fromRef, paths...)
+}
+
 func (git *mockGitCli) generateRandomLogs(numberOfLogs int) []string {
 	var result []string
 
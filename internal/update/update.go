@@ -9,28 +9,114 @@ import (
 )
 
 var ErrNotSupportedUpdateKind = errors.New("the update kind is not supported")
+var ErrNothingToUpdate = errors.New("could not find the version to update")
 
 type Update interface {
 	Run(currentContent []byte, newVersion string) ([]byte, error)
 }
 
-func Execute(nextVersion string, updateConfig config.Update) (string, []byte, error) {
-	updater, err := getUpdater(updateConfig)
-	if err != nil {
-		return "", nil, err
+// UpdatedFile is the new content of a file after all of its updates.
+type UpdatedFile struct {
+	Path    string
+	Content []byte
+}
+
+// fileUpdate is an updater with the file it updates.
+type fileUpdate struct {
+	path    string
+	updater Update
+}
+
+// Execute runs the updates in order and returns every updated file once, in the order the files first appear.
+// The updates of the same file are chained - each one works on the content the previous one produced.
+func Execute(nextVersion string, updateConfigs []config.Update) ([]UpdatedFile, error) {
+	return ExecuteOn(nil, nextVersion, updateConfigs)
+}
+
+// ExecuteOn is Execute on top of files updated before - a file in previous is updated from its content there instead
+// of the one on disk. Only the files of these updates are returned.
+func ExecuteOn(previous []UpdatedFile, nextVersion string, updateConfigs []config.Update) ([]UpdatedFile, error) {
+	result := []UpdatedFile{}
+	pathToIdx := map[string]int{}
+	previousContent := map[string][]byte{}
+	for _, file := range previous {
+		previousContent[file.Path] = file.Content
 	}
 
-	currentContent, err := os.ReadFile(updateConfig.FilePath)
-	if err != nil {
-		return "", nil, fmt.Errorf("could not find file to update on path %s with error %w", updateConfig.FilePath, err)
+	for idx, updateConfig := range updateConfigs {
+		fileUpdates, err := getFileUpdates(updateConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, fileUpd := range fileUpdates {
+			fileIdx, ok := pathToIdx[fileUpd.path]
+			if !ok {
+				currentContent, ok := previousContent[fileUpd.path]
+				if !ok {
+					currentContent, err = os.ReadFile(fileUpd.path)
+					if err != nil {
+						return nil, fmt.Errorf("could not find file to update on path %s with error %w", fileUpd.path, err)
+					}
+				}
+
+				fileIdx = len(result)
+				pathToIdx[fileUpd.path] = fileIdx
+				result = append(result, UpdatedFile{Path: fileUpd.path, Content: currentContent})
+			}
+
+			newContent, err := fileUpd.updater.Run(result[fileIdx].Content, nextVersion)
+			if err != nil {
+				return nil, fmt.Errorf("update of %s [%d] failed with %w", fileUpd.path, idx, err)
+			}
+
+			result[fileIdx].Content = newContent
+		}
+	}
+
+	return result, nil
+}
+
+// Paths returns every file the updates change once, in the order the files first appear - the same files Execute
+// returns, without updating them.
+func Paths(updateConfigs []config.Update) ([]string, error) {
+	result := []string{}
+	seen := map[string]bool{}
+
+	for _, updateConfig := range updateConfigs {
+		fileUpdates, err := getFileUpdates(updateConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, fileUpd := range fileUpdates {
+			if !seen[fileUpd.path] {
+				seen[fileUpd.path] = true
+				result = append(result, fileUpd.path)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// getFileUpdates returns the files an update changes. Most kinds change the file of the update, PYTHON finds the
+// files of the project in the directory of the update and PACKAGE_JSON also changes the package-lock.json and the
+// workspace packages depending on the package.
+func getFileUpdates(updateConfig config.Update) ([]fileUpdate, error) {
+	switch updateConfig.Kind {
+	case config.UpdateKindPython:
+		return pythonUpdates(updateConfig)
+	case config.UpdateKindPackageJson:
+		return packageJsonUpdates(updateConfig)
 	}
 
-	newContent, err := updater.Run(currentContent, nextVersion)
+	updater, err := getUpdater(updateConfig)
 	if err != nil {
-		return "", nil, fmt.Errorf("update failed with %w", err)
+		return nil, err
 	}
 
-	return updateConfig.FilePath, newContent, nil
+	return []fileUpdate{{path: updateConfig.FilePath, updater: updater}}, nil
 }
 
 func getUpdater(updateConfig config.Update) (Update, error) {
@@ -58,5 +144,35 @@ func getUpdater(updateConfig config.Update) (Update, error) {
 		}, nil
 	}
 
+	if updateConfig.Kind == config.UpdateKindRegex {
+		return &updateRegex{
+			cfg: updateConfig,
+		}, nil
+	}
+
+	if updateConfig.Kind == config.UpdateKindMarker {
+		return &updateMarker{
+			cfg: updateConfig,
+		}, nil
+	}
+
+	if updateConfig.Kind == config.UpdateKindGradle {
+		return &updateGradle{
+			cfg: updateConfig,
+		}, nil
+	}
+
+	if updateConfig.Kind == config.UpdateKindHelmChart {
+		return &updateHelmChart{
+			cfg: updateConfig,
+		}, nil
+	}
+
+	if updateConfig.Kind == config.UpdateKindDotnet {
+		return &updateDotnet{
+			cfg: updateConfig,
+		}, nil
+	}
+
 	return nil, ErrNotSupportedUpdateKind
 }
@@ -0,0 +1,51 @@
+package update
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/rikotsev/easy-release/internal/config"
+)
+
+// fourPartProperties only accept `major.minor.build.revision` - there is no place for a prerelease.
+var fourPartProperties = []string{"assemblyversion", "fileversion"}
+
+type updateDotnet struct {
+	cfg config.Update
+}
+
+// Run replaces the text of the configured MSBuild properties in a .csproj, Directory.Build.props or any other
+// MSBuild file. Only the text between the tags changes, the rest of the file is kept byte for byte.
+func (u *updateDotnet) Run(currentContent []byte, newVersion string) ([]byte, error) {
+	properties := u.cfg.MsbuildProperties
+	if len(properties) == 0 {
+		properties = []string{"Version"}
+	}
+
+	parsedVersion, err := semver.StrictNewVersion(newVersion)
+	if err != nil {
+		return nil, fmt.Errorf("version: %s is not strict semver with: %w", newVersion, err)
+	}
+	fourPartVersion := fmt.Sprintf("%d.%d.%d.0", parsedVersion.Major(), parsedVersion.Minor(), parsedVersion.Patch())
+
+	result := currentContent
+	for _, property := range properties {
+		value := newVersion
+		if slices.Contains(fourPartProperties, strings.ToLower(property)) {
+			value = fourPartVersion
+		}
+
+		// MSBuild property names are case-insensitive - `<Version Condition="...">1.0.0</Version>`
+		propertyRegex := regexp.MustCompile(`(?i)(<` + regexp.QuoteMeta(property) + `(?:\s[^>]*)?>)([^<]*)(</` + regexp.QuoteMeta(property) + `\s*>)`)
+		if !propertyRegex.Match(result) {
+			return nil, fmt.Errorf("%w: there is no %s property", ErrNothingToUpdate, property)
+		}
+
+		result = propertyRegex.ReplaceAll(result, []byte("${1}"+escapeReplacement(value)+"${3}"))
+	}
+
+	return result, nil
+}
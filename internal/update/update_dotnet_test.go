@@ -0,0 +1,92 @@
+package update
+
+import (
+	"fmt"
+
+	"github.com/rikotsev/easy-release/internal/config"
+)
+
+func (suite *UpdateTestSuite) TestDotnetUpdate() {
+	project := `<Project Sdk="Microsoft.NET.Sdk">
+
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    <!-- the released version -->
+    <Version>1.2.0</Version>
+    <VersionPrefix>1.2.0</VersionPrefix>
+    <AssemblyVersion>1.2.0.0</AssemblyVersion>
+    <FileVersion Condition=" '$(Configuration)' == 'Release' ">1.2.0.0</FileVersion>
+    <Description>Tom &amp; Jerry</Description>
+  </PropertyGroup>
+
+</Project>
+`
+
+	tests := []struct {
+		newVersion string
+		newContent string
+		cfg        config.Update
+	}{
+		{
+			newVersion: "1.3.0",
+			newContent: `<Project Sdk="Microsoft.NET.Sdk">
+
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    <!-- the released version -->
+    <Version>1.3.0</Version>
+    <VersionPrefix>1.2.0</VersionPrefix>
+    <AssemblyVersion>1.2.0.0</AssemblyVersion>
+    <FileVersion Condition=" '$(Configuration)' == 'Release' ">1.2.0.0</FileVersion>
+    <Description>Tom &amp; Jerry</Description>
+  </PropertyGroup>
+
+</Project>
+`,
+			cfg: config.Update{
+				Kind: config.UpdateKindDotnet,
+			},
+		},
+		{
+			newVersion: "2.0.0-rc.1",
+			newContent: `<Project Sdk="Microsoft.NET.Sdk">
+
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    <!-- the released version -->
+    <Version>2.0.0-rc.1</Version>
+    <VersionPrefix>2.0.0-rc.1</VersionPrefix>
+    <AssemblyVersion>2.0.0.0</AssemblyVersion>
+    <FileVersion Condition=" '$(Configuration)' == 'Release' ">2.0.0.0</FileVersion>
+    <Description>Tom &amp; Jerry</Description>
+  </PropertyGroup>
+
+</Project>
+`,
+			cfg: config.Update{
+				Kind:              config.UpdateKindDotnet,
+				MsbuildProperties: []string{"Version", "VersionPrefix", "AssemblyVersion", "FileVersion"},
+			},
+		},
+	}
+
+	for idx, testCase := range tests {
+		suite.Run(fmt.Sprintf("testing dotnet update: [%d]", idx), func() {
+			updater := updateDotnet{
+				cfg: testCase.cfg,
+			}
+
+			actual, err := updater.Run([]byte(project), testCase.newVersion)
+			suite.NoError(err)
+
+			suite.Equal(testCase.newContent, string(actual))
+		})
+	}
+
+	suite.Run("a property is missing", func() {
+		updater := updateDotnet{cfg: config.Update{MsbuildProperties: []string{"Version", "PackageVersion"}}}
+
+		_, err := updater.Run([]byte(project), "1.3.0")
+		suite.ErrorIs(err, ErrNothingToUpdate)
+	})
+}
@@ -0,0 +1,43 @@
+package update
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rikotsev/easy-release/internal/config"
+)
+
+var (
+	// gradlePropertyRegex matches the version in gradle.properties - `version=1.0.0`, `version = 1.0.0` or `version: 1.0.0`.
+	gradlePropertyRegex = regexp.MustCompile(`(?m)^([ \t]*version[ \t]*[=:][ \t]*)(\S*)(.*?)$`)
+	// gradleScriptRegex matches the version assignment of a build script at the start of a line -
+	// `version = "1.0.0"` in Kotlin, `version = '1.0.0'` or `version '1.0.0'` in Groovy and `project.version = ...` in both.
+	gradleScriptRegex = regexp.MustCompile(`(?m)^([ \t]*(?:project\.)?version(?:[ \t]*=[ \t]*|[ \t]+))(["'])([^"'\n]*)(["'])`)
+)
+
+type updateGradle struct {
+	cfg config.Update
+}
+
+// Run updates `version=` in a gradle.properties file or the version assignment in a build.gradle(.kts) file.
+func (u *updateGradle) Run(currentContent []byte, newVersion string) ([]byte, error) {
+	if strings.HasSuffix(u.cfg.FilePath, ".gradle") || strings.HasSuffix(u.cfg.FilePath, ".gradle.kts") {
+		if !gradleScriptRegex.Match(currentContent) {
+			return nil, fmt.Errorf("%w: there is no version assignment in %s", ErrNothingToUpdate, u.cfg.FilePath)
+		}
+
+		return gradleScriptRegex.ReplaceAll(currentContent, []byte("${1}${2}"+escapeReplacement(newVersion)+"${4}")), nil
+	}
+
+	if !gradlePropertyRegex.Match(currentContent) {
+		return nil, fmt.Errorf("%w: there is no version property in %s", ErrNothingToUpdate, u.cfg.FilePath)
+	}
+
+	return gradlePropertyRegex.ReplaceAll(currentContent, []byte("${1}"+escapeReplacement(newVersion)+"${3}")), nil
+}
+
+// escapeReplacement keeps a `$` in the version from being read as a group of the replacement template.
+func escapeReplacement(value string) string {
+	return strings.ReplaceAll(value, "$", "$$")
+}
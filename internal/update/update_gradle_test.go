@@ -0,0 +1,106 @@
+package update
+
+import (
+	"fmt"
+
+	"github.com/rikotsev/easy-release/internal/config"
+)
+
+func (suite *UpdateTestSuite) TestGradleUpdate() {
+	tests := []struct {
+		oldContent string
+		newVersion string
+		newContent string
+		cfg        config.Update
+	}{
+		{
+			oldContent: "group=com.example\nversion=1.0.0\nversionCode=7\norg.gradle.jvmargs=-Xmx2g\n",
+			newVersion: "1.1.0",
+			newContent: "group=com.example\nversion=1.1.0\nversionCode=7\norg.gradle.jvmargs=-Xmx2g\n",
+			cfg: config.Update{
+				Kind:     config.UpdateKindGradle,
+				FilePath: "gradle.properties",
+			},
+		},
+		{
+			oldContent: "# the release version\nversion = 1.0.0-SNAPSHOT\n",
+			newVersion: "1.0.0",
+			newContent: "# the release version\nversion = 1.0.0\n",
+			cfg: config.Update{
+				Kind:     config.UpdateKindGradle,
+				FilePath: "gradle.properties",
+			},
+		},
+		{
+			oldContent: `plugins {
+    id("org.springframework.boot") version "3.3.0"
+}
+
+group = "com.example"
+version = "1.0.0"   // managed by easy-release
+`,
+			newVersion: "1.1.0",
+			newContent: `plugins {
+    id("org.springframework.boot") version "3.3.0"
+}
+
+group = "com.example"
+version = "1.1.0"   // managed by easy-release
+`,
+			cfg: config.Update{
+				Kind:     config.UpdateKindGradle,
+				FilePath: "build.gradle.kts",
+			},
+		},
+		{
+			oldContent: `plugins {
+    id 'java'
+    id 'org.springframework.boot' version '3.3.0'
+}
+
+group 'com.example'
+version '1.0.0'
+`,
+			newVersion: "1.0.1",
+			newContent: `plugins {
+    id 'java'
+    id 'org.springframework.boot' version '3.3.0'
+}
+
+group 'com.example'
+version '1.0.1'
+`,
+			cfg: config.Update{
+				Kind:     config.UpdateKindGradle,
+				FilePath: "app/build.gradle",
+			},
+		},
+	}
+
+	for idx, testCase := range tests {
+		suite.Run(fmt.Sprintf("testing gradle update: [%d]", idx), func() {
+			updater := updateGradle{
+				cfg: testCase.cfg,
+			}
+
+			actual, err := updater.Run([]byte(testCase.oldContent), testCase.newVersion)
+			suite.NoError(err)
+
+			suite.Equal(testCase.newContent, string(actual))
+		})
+	}
+
+	suite.Run("there is no version property", func() {
+		updater := updateGradle{cfg: config.Update{FilePath: "gradle.properties"}}
+
+		_, err := updater.Run([]byte("group=com.example\n"), "1.0.0")
+		suite.ErrorIs(err, ErrNothingToUpdate)
+	})
+
+	suite.Run("there is no version assignment", func() {
+		updater := updateGradle{cfg: config.Update{FilePath: "build.gradle.kts"}}
+
+		_, err := updater.Run([]byte("plugins {\n    id(\"x\") version \"1.0\"\n}\n"), "1.0.0")
+		suite.ErrorIs(err, ErrNothingToUpdate)
+	})
+}
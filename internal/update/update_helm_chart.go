@@ -0,0 +1,69 @@
+package update
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/rikotsev/easy-release/internal/config"
+)
+
+type updateHelmChart struct {
+	cfg config.Update
+}
+
+// Run updates the top level `version` and `appVersion` of a Chart.yaml keeping the quotes and comments as they are.
+func (u *updateHelmChart) Run(currentContent []byte, newVersion string) ([]byte, error) {
+	result := currentContent
+
+	if !u.cfg.KeepAppVersion {
+		appVersion, err := replaceChartKey(result, "appVersion", func(string) (string, error) {
+			return newVersion, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		result = appVersion
+	}
+
+	switch u.cfg.ChartVersion {
+	case "", config.ChartVersionRelease:
+		return replaceChartKey(result, "version", func(string) (string, error) {
+			return newVersion, nil
+		})
+	case config.ChartVersionPatch:
+		return replaceChartKey(result, "version", func(current string) (string, error) {
+			chartVersion, err := semver.StrictNewVersion(current)
+			if err != nil {
+				return "", fmt.Errorf("the chart version: %s is not strict semver with: %w", current, err)
+			}
+
+			return chartVersion.IncPatch().String(), nil
+		})
+	case config.ChartVersionNone:
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("unknown chart version: %s", u.cfg.ChartVersion)
+}
+
+// replaceChartKey replaces the value of a top level key with what next returns for the current value.
+func replaceChartKey(content []byte, key string, next func(current string) (string, error)) ([]byte, error) {
+	keyRegex := regexp.MustCompile(`(?m)^(` + regexp.QuoteMeta(key) + `:[ \t]*)(["']?)([^"'\s#]*)(["']?)`)
+
+	match := keyRegex.FindSubmatchIndex(content)
+	if match == nil {
+		return nil, fmt.Errorf("%w: there is no %s in the chart", ErrNothingToUpdate, key)
+	}
+
+	value, err := next(string(content[match[6]:match[7]]))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]byte, 0, len(content)+len(value))
+	result = append(result, content[:match[6]]...)
+	result = append(result, value...)
+
+	return append(result, content[match[7]:]...), nil
+}
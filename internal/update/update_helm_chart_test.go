@@ -0,0 +1,126 @@
+package update
+
+import (
+	"fmt"
+
+	"github.com/rikotsev/easy-release/internal/config"
+)
+
+func (suite *UpdateTestSuite) TestHelmChartUpdate() {
+	chart := `apiVersion: v2
+name: app
+description: A Helm chart for the app
+type: application
+# the chart version
+version: 0.4.2
+appVersion: "1.2.0" # the image tag
+dependencies:
+  - name: redis
+    version: 19.0.0
+`
+
+	tests := []struct {
+		newVersion string
+		newContent string
+		cfg        config.Update
+	}{
+		{
+			newVersion: "1.3.0",
+			newContent: `apiVersion: v2
+name: app
+description: A Helm chart for the app
+type: application
+# the chart version
+version: 1.3.0
+appVersion: "1.3.0" # the image tag
+dependencies:
+  - name: redis
+    version: 19.0.0
+`,
+			cfg: config.Update{
+				Kind: config.UpdateKindHelmChart,
+			},
+		},
+		{
+			newVersion: "1.3.0",
+			newContent: `apiVersion: v2
+name: app
+description: A Helm chart for the app
+type: application
+# the chart version
+version: 0.4.3
+appVersion: "1.3.0" # the image tag
+dependencies:
+  - name: redis
+    version: 19.0.0
+`,
+			cfg: config.Update{
+				Kind:         config.UpdateKindHelmChart,
+				ChartVersion: config.ChartVersionPatch,
+			},
+		},
+		{
+			newVersion: "1.3.0",
+			newContent: `apiVersion: v2
+name: app
+description: A Helm chart for the app
+type: application
+# the chart version
+version: 1.3.0
+appVersion: "1.2.0" # the image tag
+dependencies:
+  - name: redis
+    version: 19.0.0
+`,
+			cfg: config.Update{
+				Kind:           config.UpdateKindHelmChart,
+				KeepAppVersion: true,
+			},
+		},
+		{
+			newVersion: "1.3.0",
+			newContent: `apiVersion: v2
+name: app
+description: A Helm chart for the app
+type: application
+# the chart version
+version: 0.4.2
+appVersion: "1.3.0" # the image tag
+dependencies:
+  - name: redis
+    version: 19.0.0
+`,
+			cfg: config.Update{
+				Kind:         config.UpdateKindHelmChart,
+				ChartVersion: config.ChartVersionNone,
+			},
+		},
+	}
+
+	for idx, testCase := range tests {
+		suite.Run(fmt.Sprintf("testing helm chart update: [%d]", idx), func() {
+			updater := updateHelmChart{
+				cfg: testCase.cfg,
+			}
+
+			actual, err := updater.Run([]byte(chart), testCase.newVersion)
+			suite.NoError(err)
+
+			suite.Equal(testCase.newContent, string(actual))
+		})
+	}
+
+	suite.Run("the chart has no appVersion", func() {
+		updater := updateHelmChart{cfg: config.Update{}}
+
+		_, err := updater.Run([]byte("apiVersion: v2\nname: app\nversion: 0.1.0\n"), "1.0.0")
+		suite.ErrorIs(err, ErrNothingToUpdate)
+	})
+
+	suite.Run("the chart version is not semver", func() {
+		updater := updateHelmChart{cfg: config.Update{ChartVersion: config.ChartVersionPatch}}
+
+		_, err := updater.Run([]byte("version: latest\nappVersion: 1.0.0\n"), "1.1.0")
+		suite.Error(err)
+	})
+}
@@ -0,0 +1,58 @@
+package update
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rikotsev/easy-release/internal/config"
+)
+
+const defaultVersionMarker = "x-easy-release-version"
+
+// semverRegex matches a semantic version anywhere in a line - `1.2.3`, `1.2.3-rc.1` or `1.2.3+build.5`. A `v` in front is kept.
+var semverRegex = regexp.MustCompile(`\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?`)
+
+type updateMarker struct {
+	cfg config.Update
+}
+
+// Run replaces the first version on every line annotated with the marker - `ENV VERSION=1.2.3 # x-easy-release-version`.
+func (u *updateMarker) Run(currentContent []byte, newVersion string) ([]byte, error) {
+	marker := u.cfg.Marker
+	if marker == "" {
+		marker = defaultVersionMarker
+	}
+
+	lines := strings.SplitAfter(string(currentContent), "\n")
+	updated := 0
+
+	for idx, line := range lines {
+		markerIdx := strings.Index(line, marker)
+		if markerIdx == -1 {
+			continue
+		}
+
+		// the version has to be before the marker or after it, but not part of it
+		versionIdx := semverRegex.FindStringIndex(line[:markerIdx])
+		offset := 0
+		if versionIdx == nil {
+			offset = markerIdx + len(marker)
+			versionIdx = semverRegex.FindStringIndex(line[offset:])
+		}
+
+		if versionIdx == nil {
+			return nil, fmt.Errorf("%w: line %d has the marker %s but no version", ErrNothingToUpdate, idx+1, marker)
+		}
+
+		start, end := offset+versionIdx[0], offset+versionIdx[1]
+		lines[idx] = line[:start] + newVersion + line[end:]
+		updated++
+	}
+
+	if updated == 0 {
+		return nil, fmt.Errorf("%w: no line has the marker %s", ErrNothingToUpdate, marker)
+	}
+
+	return []byte(strings.Join(lines, "")), nil
+}
@@ -0,0 +1,69 @@
+package update
+
+import (
+	"fmt"
+
+	"github.com/rikotsev/easy-release/internal/config"
+)
+
+func (suite *UpdateTestSuite) TestMarkerUpdate() {
+	tests := []struct {
+		oldContent string
+		newVersion string
+		newContent string
+		cfg        config.Update
+	}{
+		{
+			oldContent: "FROM alpine:3.20\nENV APP_VERSION=1.2.3 # x-easy-release-version\nRUN echo 1.2.3\n",
+			newVersion: "1.3.0",
+			newContent: "FROM alpine:3.20\nENV APP_VERSION=1.3.0 # x-easy-release-version\nRUN echo 1.2.3\n",
+			cfg: config.Update{
+				Kind: config.UpdateKindMarker,
+			},
+		},
+		{
+			oldContent: "image:\n  repository: app\n  tag: v1.2.3-rc.1 # x-easy-release-version\n  sidecar: 1.2.3 # x-easy-release-version\n",
+			newVersion: "1.2.3",
+			newContent: "image:\n  repository: app\n  tag: v1.2.3 # x-easy-release-version\n  sidecar: 1.2.3 # x-easy-release-version\n",
+			cfg: config.Update{
+				Kind: config.UpdateKindMarker,
+			},
+		},
+		{
+			oldContent: "<!-- release-version --> Install with `go install example.com/app@v0.9.0`\n",
+			newVersion: "1.0.0",
+			newContent: "<!-- release-version --> Install with `go install example.com/app@v1.0.0`\n",
+			cfg: config.Update{
+				Kind:   config.UpdateKindMarker,
+				Marker: "release-version",
+			},
+		},
+	}
+
+	for idx, testCase := range tests {
+		suite.Run(fmt.Sprintf("testing marker update: [%d]", idx), func() {
+			updater := updateMarker{
+				cfg: testCase.cfg,
+			}
+
+			actual, err := updater.Run([]byte(testCase.oldContent), testCase.newVersion)
+			suite.NoError(err)
+
+			suite.Equal(testCase.newContent, string(actual))
+		})
+	}
+
+	suite.Run("no line has the marker", func() {
+		updater := updateMarker{cfg: config.Update{}}
+
+		_, err := updater.Run([]byte("version: 1.0.0\n"), "1.1.0")
+		suite.ErrorIs(err, ErrNothingToUpdate)
+	})
+
+	suite.Run("a marked line has no version", func() {
+		updater := updateMarker{cfg: config.Update{}}
+
+		_, err := updater.Run([]byte("version: latest # x-easy-release-version\n"), "1.1.0")
+		suite.ErrorIs(err, ErrNothingToUpdate)
+	})
+}
@@ -2,10 +2,44 @@ package update
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
 	"github.com/rikotsev/easy-release/internal/config"
 	"github.com/tidwall/sjson"
 )
 
+const packageLockFileName = "package-lock.json"
+
+var (
+	// npmRangeRegex matches the dependency ranges that can follow a released version - `1.2.0`, `^1.2.0` or `~1.2.0`.
+	// Anything else - `*`, `file:`, `workspace:` or a range with several versions - is left as it is.
+	npmRangeRegex = regexp.MustCompile(`^(\^|~|=|>=)?v?\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?$`)
+	// npmDependencyFields are the fields of a manifest with ranges of other packages, in the order they are updated.
+	npmDependencyFields = []string{"dependencies", "devDependencies", "peerDependencies", "optionalDependencies"}
+)
+
+// npmManifest is the part of a package.json, or of a package in a package-lock.json, the updates work with.
+type npmManifest struct {
+	Name                 string            `json:"name"`
+	Version              *string           `json:"version"`
+	Workspaces           json.RawMessage   `json:"workspaces"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+type npmPackageLock struct {
+	Version  *string                `json:"version"`
+	Packages map[string]npmManifest `json:"packages"`
+}
+
 type updatePackageJson struct {
 	cfg config.Update
 }
@@ -27,3 +61,278 @@ func (u *updatePackageJson) Run(currentContent []byte, newVersion string) ([]byt
 
 	return currentContent, nil
 }
+
+// updatePackageLock updates the version of the released package in a package-lock.json and the ranges the other
+// packages of the workspace depend on it with.
+type updatePackageLock struct {
+	location string // the path of the package relative to the lock file - empty for the root package
+	name     string
+}
+
+func (u *updatePackageLock) Run(currentContent []byte, newVersion string) ([]byte, error) {
+	var lock npmPackageLock
+	if err := json.Unmarshal(currentContent, &lock); err != nil {
+		return nil, fmt.Errorf("could not parse %s with: %w", packageLockFileName, err)
+	}
+
+	output := string(currentContent)
+	var err error
+
+	if u.location == "" && lock.Version != nil {
+		if output, err = sjson.Set(output, "version", newVersion); err != nil {
+			return nil, err
+		}
+	}
+
+	if pkg, ok := lock.Packages[u.location]; ok && pkg.Version != nil {
+		if output, err = sjson.Set(output, "packages."+escapeJsonPath(u.location)+".version", newVersion); err != nil {
+			return nil, err
+		}
+	}
+
+	locations := make([]string, 0, len(lock.Packages))
+	for location := range lock.Packages {
+		// the installed packages are not part of the workspace
+		if location != u.location && !strings.Contains(location, "node_modules/") {
+			locations = append(locations, location)
+		}
+	}
+	sort.Strings(locations)
+
+	for _, location := range locations {
+		output, err = setDependencyRanges(output, "packages."+escapeJsonPath(location)+".", lock.Packages[location], u.name, newVersion)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return []byte(output), nil
+}
+
+// updateDependencyRange updates the ranges a package.json depends on the released package with.
+type updateDependencyRange struct {
+	name string
+}
+
+func (u *updateDependencyRange) Run(currentContent []byte, newVersion string) ([]byte, error) {
+	var manifest npmManifest
+	if err := json.Unmarshal(currentContent, &manifest); err != nil {
+		return nil, err
+	}
+
+	output, err := setDependencyRanges(string(currentContent), "", manifest, u.name, newVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(output), nil
+}
+
+// packageJsonUpdates returns the update of the package.json together with the nearest package-lock.json and the
+// package.json files of the workspace depending on the package.
+func packageJsonUpdates(updateConfig config.Update) ([]fileUpdate, error) {
+	result := []fileUpdate{{path: updateConfig.FilePath, updater: &updatePackageJson{cfg: updateConfig}}}
+
+	manifest, found, err := readNpmManifest(updateConfig.FilePath)
+	if err != nil || !found {
+		// the update of the package.json itself reports what is wrong with it
+		return result, nil
+	}
+
+	packageDir := filepath.Dir(updateConfig.FilePath)
+	lockFound, workspaceFound := false, false
+
+	for _, dir := range ancestors(packageDir) {
+		lockPath := filepath.Join(dir, packageLockFileName)
+		if _, err := os.Stat(lockPath); !lockFound && err == nil {
+			location, err := filepath.Rel(dir, packageDir)
+			if err != nil {
+				return nil, fmt.Errorf("could not locate %s in %s with: %w", updateConfig.FilePath, lockPath, err)
+			}
+			if location == "." {
+				location = ""
+			}
+
+			lockFound = true
+			result = append(result, fileUpdate{
+				path:    lockPath,
+				updater: &updatePackageLock{location: filepath.ToSlash(location), name: manifest.Name},
+			})
+		}
+
+		if !workspaceFound && manifest.Name != "" {
+			dependents, isWorkspace, err := workspaceDependents(dir, manifest.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			if isWorkspace {
+				workspaceFound = true
+				for _, dependent := range dependents {
+					if filepath.Clean(dependent) != filepath.Clean(updateConfig.FilePath) {
+						result = append(result, fileUpdate{path: dependent, updater: &updateDependencyRange{name: manifest.Name}})
+					}
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// workspaceDependents returns the package.json files of the workspace rooted in the directory, the root one included,
+// that depend on the package. The second result is false when the directory is not the root of a workspace.
+func workspaceDependents(dir string, name string) ([]string, bool, error) {
+	rootPath := filepath.Join(dir, "package.json")
+	root, found, err := readNpmManifest(rootPath)
+	if err != nil || !found {
+		return nil, false, err
+	}
+
+	patterns, err := workspacePatterns(root.Workspaces)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not parse the workspaces of %s with: %w", rootPath, err)
+	}
+	if patterns == nil {
+		return nil, false, nil
+	}
+
+	result := []string{}
+	if dependsOn(root, name) {
+		result = append(result, rootPath)
+	}
+
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(pattern), "package.json"))
+		if err != nil {
+			return nil, false, fmt.Errorf("could not look for the workspace: %s with: %w", pattern, err)
+		}
+
+		for _, match := range matches {
+			manifest, found, err := readNpmManifest(match)
+			if err != nil {
+				return nil, false, err
+			}
+
+			if found && dependsOn(manifest, name) {
+				result = append(result, match)
+			}
+		}
+	}
+
+	return result, true, nil
+}
+
+// workspacePatterns reads the workspaces of a package.json - `["packages/*"]` or `{"packages": ["packages/*"]}`.
+// Negated patterns are skipped.
+func workspacePatterns(workspaces json.RawMessage) ([]string, error) {
+	if len(workspaces) == 0 {
+		return nil, nil
+	}
+
+	patterns := []string{}
+	if err := json.Unmarshal(workspaces, &patterns); err != nil {
+		var object struct {
+			Packages []string `json:"packages"`
+		}
+		if err := json.Unmarshal(workspaces, &object); err != nil {
+			return nil, err
+		}
+		patterns = object.Packages
+	}
+
+	result := []string{}
+	for _, pattern := range patterns {
+		if !strings.HasPrefix(pattern, "!") {
+			result = append(result, pattern)
+		}
+	}
+
+	return result, nil
+}
+
+func readNpmManifest(manifestPath string) (npmManifest, bool, error) {
+	var manifest npmManifest
+
+	content, err := os.ReadFile(manifestPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, false, nil
+	}
+	if err != nil {
+		return manifest, false, fmt.Errorf("could not read: %s with: %w", manifestPath, err)
+	}
+
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return manifest, false, fmt.Errorf("could not parse: %s with: %w", manifestPath, err)
+	}
+
+	return manifest, true, nil
+}
+
+// dependencyRanges returns the ranges of the dependency fields by field.
+func (m npmManifest) dependencyRanges() map[string]map[string]string {
+	return map[string]map[string]string{
+		"dependencies":         m.Dependencies,
+		"devDependencies":      m.DevDependencies,
+		"peerDependencies":     m.PeerDependencies,
+		"optionalDependencies": m.OptionalDependencies,
+	}
+}
+
+func dependsOn(manifest npmManifest, name string) bool {
+	for _, ranges := range manifest.dependencyRanges() {
+		if npmRangeRegex.MatchString(ranges[name]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setDependencyRanges points the ranges of the manifest found under the json path prefix at the new version,
+// keeping their operator - `^1.2.0` becomes `^1.3.0`.
+func setDependencyRanges(content string, pathPrefix string, manifest npmManifest, name string, newVersion string) (string, error) {
+	ranges := manifest.dependencyRanges()
+
+	for _, field := range npmDependencyFields {
+		matches := npmRangeRegex.FindStringSubmatch(ranges[field][name])
+		if matches == nil {
+			continue
+		}
+
+		var err error
+		content, err = sjson.Set(content, pathPrefix+field+"."+escapeJsonPath(name), matches[1]+newVersion)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return content, nil
+}
+
+// escapeJsonPath escapes a key for an sjson path - `packages/a.js` to `packages/a\.js`.
+func escapeJsonPath(key string) string {
+	var result strings.Builder
+	for _, char := range key {
+		if char == '.' || char == '*' || char == '?' || char == '\\' {
+			result.WriteRune('\\')
+		}
+		result.WriteRune(char)
+	}
+
+	return result.String()
+}
+
+// ancestors returns the directory followed by its parents.
+func ancestors(dir string) []string {
+	result := []string{dir}
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return result
+		}
+
+		result = append(result, parent)
+		dir = parent
+	}
+}
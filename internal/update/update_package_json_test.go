@@ -2,6 +2,9 @@ package update
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+
 	"github.com/rikotsev/easy-release/internal/config"
 )
 
@@ -201,3 +204,86 @@ func (suite *UpdateTestSuite) TestPackageJsonUpdate() {
 		})
 	}
 }
+
+func (suite *UpdateTestSuite) TestPackageLockIsUpdated() {
+	writeFile := func(path string, content string) {
+		suite.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		suite.Require().NoError(os.WriteFile(path, []byte(content), 0644))
+	}
+
+	suite.Run("the root package", func() {
+		dir := suite.T().TempDir()
+		writeFile(filepath.Join(dir, "package.json"), `{"name": "app", "version": "1.0.0"}`)
+		writeFile(filepath.Join(dir, "package-lock.json"), `{
+  "name": "app",
+  "version": "1.0.0",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "version": "1.0.0", "dependencies": {"left-pad": "^1.0.0"}},
+    "node_modules/left-pad": {"version": "1.0.0"}
+  }
+}`)
+
+		updatedFiles, err := Execute("1.1.0", []config.Update{{FilePath: filepath.Join(dir, "package.json"), Kind: config.UpdateKindPackageJson}})
+
+		suite.Require().NoError(err)
+		suite.Equal([]UpdatedFile{
+			{Path: filepath.Join(dir, "package.json"), Content: []byte(`{"name": "app", "version": "1.1.0"}`)},
+			{Path: filepath.Join(dir, "package-lock.json"), Content: []byte(`{
+  "name": "app",
+  "version": "1.1.0",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "version": "1.1.0", "dependencies": {"left-pad": "^1.0.0"}},
+    "node_modules/left-pad": {"version": "1.0.0"}
+  }
+}`)},
+		}, updatedFiles)
+	})
+
+	suite.Run("a workspace package", func() {
+		dir := suite.T().TempDir()
+		writeFile(filepath.Join(dir, "package.json"), `{"name": "root", "workspaces": {"packages": ["packages/*"]}, "devDependencies": {"@org/api": "*"}}`)
+		writeFile(filepath.Join(dir, "packages", "api", "package.json"), `{"name": "@org/api", "version": "1.0.0"}`)
+		writeFile(filepath.Join(dir, "packages", "web", "package.json"), `{"name": "web", "version": "0.1.0", "dependencies": {"@org/api": "~1.0.0"}, "peerDependencies": {"@org/api": "1.0.0"}}`)
+		writeFile(filepath.Join(dir, "packages", "docs", "package.json"), `{"name": "docs", "version": "0.1.0", "dependencies": {"@org/api": "workspace:*"}}`)
+		writeFile(filepath.Join(dir, "package-lock.json"), `{
+  "name": "root",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "root", "workspaces": ["packages/*"], "devDependencies": {"@org/api": "*"}},
+    "node_modules/@org/api": {"resolved": "packages/api", "link": true},
+    "packages/api": {"name": "@org/api", "version": "1.0.0"},
+    "packages/web": {"version": "0.1.0", "dependencies": {"@org/api": "~1.0.0"}, "peerDependencies": {"@org/api": "1.0.0"}}
+  }
+}`)
+
+		updatedFiles, err := Execute("1.1.0", []config.Update{{FilePath: filepath.Join(dir, "packages", "api", "package.json"), Kind: config.UpdateKindPackageJson}})
+
+		suite.Require().NoError(err)
+		suite.Equal([]UpdatedFile{
+			{Path: filepath.Join(dir, "packages", "api", "package.json"), Content: []byte(`{"name": "@org/api", "version": "1.1.0"}`)},
+			{Path: filepath.Join(dir, "package-lock.json"), Content: []byte(`{
+  "name": "root",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "root", "workspaces": ["packages/*"], "devDependencies": {"@org/api": "*"}},
+    "node_modules/@org/api": {"resolved": "packages/api", "link": true},
+    "packages/api": {"name": "@org/api", "version": "1.1.0"},
+    "packages/web": {"version": "0.1.0", "dependencies": {"@org/api": "~1.1.0"}, "peerDependencies": {"@org/api": "1.1.0"}}
+  }
+}`)},
+			{Path: filepath.Join(dir, "packages", "web", "package.json"), Content: []byte(`{"name": "web", "version": "0.1.0", "dependencies": {"@org/api": "~1.1.0"}, "peerDependencies": {"@org/api": "1.1.0"}}`)},
+		}, updatedFiles)
+	})
+
+	suite.Run("a package without a lock file", func() {
+		dir := suite.T().TempDir()
+		writeFile(filepath.Join(dir, "package.json"), `{"name": "app", "version": "1.0.0"}`)
+
+		updatedFiles, err := Execute("1.1.0", []config.Update{{FilePath: filepath.Join(dir, "package.json"), Kind: config.UpdateKindPackageJson}})
+
+		suite.Require().NoError(err)
+		suite.Len(updatedFiles, 1)
+	})
+}
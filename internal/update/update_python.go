@@ -0,0 +1,199 @@
+package update
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/rikotsev/easy-release/internal/config"
+)
+
+var (
+	// pythonVersionRegex matches a `__version__ = "1.2.3"` assignment.
+	pythonVersionRegex = regexp.MustCompile(`(?m)^(__version__[ \t]*(?::[ \t]*str[ \t]*)?=[ \t]*)(["'])([^"'\n]*)(["'])`)
+	// setupCfgSectionRegex matches an ini section header - `[metadata]`.
+	setupCfgSectionRegex = regexp.MustCompile(`^\[([^\]]+)\]`)
+	// setupCfgVersionRegex matches the version option - `version = 1.2.3`, but not `version = attr: app.__version__`.
+	setupCfgVersionRegex = regexp.MustCompile(`^(version[ \t]*[=:][ \t]*)([^\s:]+)([ \t]*(?:[#;].*)?\r?\n?)$`)
+	// pep440Labels are the PEP 440 forms of the prerelease labels - `1.2.0-rc.1` is `1.2.0rc1`.
+	pep440Labels = map[string]string{
+		"a":       "a",
+		"alpha":   "a",
+		"b":       "b",
+		"beta":    "b",
+		"c":       "rc",
+		"rc":      "rc",
+		"pre":     "rc",
+		"preview": "rc",
+		"dev":     ".dev",
+		"post":    ".post",
+	}
+)
+
+// pep440 updates a file with the PEP 440 form of the version.
+type pep440 struct {
+	updater Update
+}
+
+func (p *pep440) Run(currentContent []byte, newVersion string) ([]byte, error) {
+	pythonVersion, err := Pep440Version(newVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.updater.Run(currentContent, pythonVersion)
+}
+
+// Pep440Version converts a semantic version to the PEP 440 one - `1.2.0-rc.1` to `1.2.0rc1`, `1.2.0-beta` to `1.2.0b0`.
+func Pep440Version(version string) (string, error) {
+	parsed, err := semver.StrictNewVersion(version)
+	if err != nil {
+		return "", fmt.Errorf("version: %s is not strict semver with: %w", version, err)
+	}
+
+	result := fmt.Sprintf("%d.%d.%d", parsed.Major(), parsed.Minor(), parsed.Patch())
+
+	if prerelease := parsed.Prerelease(); prerelease != "" {
+		label, number, _ := strings.Cut(prerelease, ".")
+		pythonLabel, ok := pep440Labels[strings.ToLower(label)]
+		if !ok {
+			return "", fmt.Errorf("the prerelease: %s of %s has no PEP 440 form", prerelease, version)
+		}
+
+		if number == "" {
+			number = "0"
+		}
+		if strings.ContainsFunc(number, func(r rune) bool { return r < '0' || r > '9' }) {
+			return "", fmt.Errorf("the prerelease: %s of %s has no PEP 440 form", prerelease, version)
+		}
+
+		result += pythonLabel + number
+	}
+
+	if metadata := parsed.Metadata(); metadata != "" {
+		result += "+" + metadata
+	}
+
+	return result, nil
+}
+
+// pythonUpdates finds the files declaring the version of the Python project in the directory of the update -
+// the pyproject.toml (PEP 621 or Poetry), the setup.cfg and the `__version__` of the packages.
+func pythonUpdates(updateConfig config.Update) ([]fileUpdate, error) {
+	dir := updateConfig.FilePath
+	if dir == "" {
+		dir = "."
+	}
+
+	result := []fileUpdate{}
+
+	pyproject := path.Join(dir, "pyproject.toml")
+	if content, err := readIfExists(pyproject); err != nil {
+		return nil, err
+	} else if content != nil {
+		for _, keyPath := range []string{"project.version", "tool.poetry.version"} {
+			if tomlHasString(content, keyPath) {
+				result = append(result, fileUpdate{
+					path:    pyproject,
+					updater: &pep440{updater: &updateToml{cfg: config.Update{TomlPath: keyPath}}},
+				})
+			}
+		}
+	}
+
+	setupCfg := path.Join(dir, "setup.cfg")
+	if content, err := readIfExists(setupCfg); err != nil {
+		return nil, err
+	} else if content != nil && setupCfgHasVersion(content) {
+		result = append(result, fileUpdate{path: setupCfg, updater: &pep440{updater: &updateSetupCfg{}}})
+	}
+
+	for _, pattern := range []string{"*/__init__.py", "*/_version.py", "src/*/__init__.py", "src/*/_version.py"} {
+		matches, err := filepath.Glob(filepath.Join(filepath.FromSlash(dir), filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, fmt.Errorf("could not look for python packages with: %w", err)
+		}
+
+		for _, match := range matches {
+			content, err := os.ReadFile(match)
+			if err != nil {
+				return nil, fmt.Errorf("could not read: %s with: %w", match, err)
+			}
+
+			if pythonVersionRegex.Match(content) {
+				result = append(result, fileUpdate{path: filepath.ToSlash(match), updater: &pep440{updater: &updateDunderVersion{}}})
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("%w: there is no pyproject.toml, setup.cfg or __version__ with a version in %s", ErrNothingToUpdate, dir)
+	}
+
+	return result, nil
+}
+
+func readIfExists(filePath string) ([]byte, error) {
+	content, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read: %s with: %w", filePath, err)
+	}
+
+	return content, nil
+}
+
+// updateDunderVersion updates the `__version__` of a Python module.
+type updateDunderVersion struct{}
+
+func (u *updateDunderVersion) Run(currentContent []byte, newVersion string) ([]byte, error) {
+	if !pythonVersionRegex.Match(currentContent) {
+		return nil, fmt.Errorf("%w: there is no __version__", ErrNothingToUpdate)
+	}
+
+	return pythonVersionRegex.ReplaceAll(currentContent, []byte("${1}${2}"+escapeReplacement(newVersion)+"${4}")), nil
+}
+
+// updateSetupCfg updates the version in the `[metadata]` section of a setup.cfg.
+type updateSetupCfg struct{}
+
+func (u *updateSetupCfg) Run(currentContent []byte, newVersion string) ([]byte, error) {
+	lines := strings.SplitAfter(string(currentContent), "\n")
+
+	idx := setupCfgVersionLine(lines)
+	if idx == -1 {
+		return nil, fmt.Errorf("%w: there is no version in the metadata of setup.cfg", ErrNothingToUpdate)
+	}
+
+	lines[idx] = setupCfgVersionRegex.ReplaceAllString(lines[idx], "${1}"+escapeReplacement(newVersion)+"${3}")
+
+	return []byte(strings.Join(lines, "")), nil
+}
+
+func setupCfgHasVersion(content []byte) bool {
+	return setupCfgVersionLine(strings.SplitAfter(string(content), "\n")) != -1
+}
+
+func setupCfgVersionLine(lines []string) int {
+	section := ""
+	for idx, line := range lines {
+		if matches := setupCfgSectionRegex.FindStringSubmatch(line); matches != nil {
+			section = strings.TrimSpace(matches[1])
+			continue
+		}
+
+		if section == "metadata" && setupCfgVersionRegex.MatchString(line) {
+			return idx
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,123 @@
+package update
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/rikotsev/easy-release/internal/config"
+)
+
+func (suite *UpdateTestSuite) TestPythonUpdate() {
+	writeFile := func(path string, content string) {
+		suite.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		suite.Require().NoError(os.WriteFile(path, []byte(content), 0644))
+	}
+
+	suite.Run("every declaration of the version is updated", func() {
+		dir := suite.T().TempDir()
+		writeFile(filepath.Join(dir, "pyproject.toml"), `[project]
+name = "app"
+version = "1.2.0" # the released version
+
+[tool.poetry]
+version = "1.2.0"
+`)
+		writeFile(filepath.Join(dir, "setup.cfg"), `[metadata]
+name = app
+version = 1.2.0
+
+[options]
+version = 0.0.1
+`)
+		writeFile(filepath.Join(dir, "src", "app", "__init__.py"), "__version__ = '1.2.0'\n")
+		writeFile(filepath.Join(dir, "tests", "__init__.py"), "")
+
+		updatedFiles, err := Execute("1.3.0-rc.1+build.5", []config.Update{{FilePath: dir, Kind: config.UpdateKindPython}})
+
+		suite.Require().NoError(err)
+		suite.Equal([]UpdatedFile{
+			{Path: filepath.Join(dir, "pyproject.toml"), Content: []byte(`[project]
+name = "app"
+version = "1.3.0rc1+build.5" # the released version
+
+[tool.poetry]
+version = "1.3.0rc1+build.5"
+`)},
+			{Path: filepath.Join(dir, "setup.cfg"), Content: []byte(`[metadata]
+name = app
+version = 1.3.0rc1+build.5
+
+[options]
+version = 0.0.1
+`)},
+			{Path: filepath.Join(dir, "src", "app", "__init__.py"), Content: []byte("__version__ = '1.3.0rc1+build.5'\n")},
+		}, updatedFiles)
+	})
+
+	suite.Run("the paths are the updated files", func() {
+		dir := suite.T().TempDir()
+		writeFile(filepath.Join(dir, "pyproject.toml"), "[project]\nversion = \"1.2.0\"\n\n[tool.poetry]\nversion = \"1.2.0\"\n")
+		writeFile(filepath.Join(dir, "app", "__init__.py"), "__version__ = '1.2.0'\n")
+
+		paths, err := Paths([]config.Update{{FilePath: dir, Kind: config.UpdateKindPython}})
+
+		suite.Require().NoError(err)
+		suite.Equal([]string{filepath.Join(dir, "pyproject.toml"), filepath.Join(dir, "app", "__init__.py")}, paths)
+	})
+
+	suite.Run("dynamic versions are left as they are", func() {
+		dir := suite.T().TempDir()
+		writeFile(filepath.Join(dir, "pyproject.toml"), "[project]\nname = \"app\"\ndynamic = [\"version\"]\n")
+		writeFile(filepath.Join(dir, "setup.cfg"), "[metadata]\nversion = attr: app.__version__\n")
+		writeFile(filepath.Join(dir, "app", "_version.py"), "__version__: str = \"1.2.0\"\n")
+
+		updatedFiles, err := Execute("1.3.0", []config.Update{{FilePath: dir, Kind: config.UpdateKindPython}})
+
+		suite.Require().NoError(err)
+		suite.Equal([]UpdatedFile{
+			{Path: filepath.Join(dir, "app", "_version.py"), Content: []byte("__version__: str = \"1.3.0\"\n")},
+		}, updatedFiles)
+	})
+
+	suite.Run("a project without a version", func() {
+		dir := suite.T().TempDir()
+		writeFile(filepath.Join(dir, "pyproject.toml"), "[project]\nname = \"app\"\n")
+
+		_, err := Execute("1.3.0", []config.Update{{FilePath: dir, Kind: config.UpdateKindPython}})
+
+		suite.ErrorIs(err, ErrNothingToUpdate)
+	})
+}
+
+func (suite *UpdateTestSuite) TestPep440Version() {
+	tests := []struct {
+		version string
+		result  string
+	}{
+		{version: "1.2.0", result: "1.2.0"},
+		{version: "1.2.0-rc.1", result: "1.2.0rc1"},
+		{version: "1.2.0-alpha.3", result: "1.2.0a3"},
+		{version: "1.2.0-beta", result: "1.2.0b0"},
+		{version: "1.2.0-dev.4", result: "1.2.0.dev4"},
+		{version: "1.2.0-post.1", result: "1.2.0.post1"},
+		{version: "1.2.0+build.7", result: "1.2.0+build.7"},
+	}
+
+	for _, test := range tests {
+		suite.Run(test.version, func() {
+			result, err := Pep440Version(test.version)
+			suite.Require().NoError(err)
+			suite.Equal(test.result, result)
+		})
+	}
+
+	suite.Run("a prerelease without a PEP 440 form", func() {
+		_, err := Pep440Version("1.2.0-snapshot.1")
+		suite.Error(err)
+	})
+
+	suite.Run("a prerelease with more than a number", func() {
+		_, err := Pep440Version("1.2.0-rc.1.2")
+		suite.Error(err)
+	})
+}
@@ -0,0 +1,52 @@
+package update
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/rikotsev/easy-release/internal/config"
+)
+
+type updateRegex struct {
+	cfg config.Update
+}
+
+func (u *updateRegex) Run(currentContent []byte, newVersion string) ([]byte, error) {
+	pattern, err := regexp.Compile(u.cfg.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("could not compile pattern: %s with: %w", u.cfg.Pattern, err)
+	}
+
+	group := u.cfg.Group
+	if group == 0 {
+		group = 1
+	}
+
+	if group > pattern.NumSubexp() {
+		return nil, fmt.Errorf("pattern: %s has no group %d", u.cfg.Pattern, group)
+	}
+
+	matches := pattern.FindAllSubmatchIndex(currentContent, -1)
+
+	result := make([]byte, 0, len(currentContent))
+	last := 0
+	updated := 0
+	for _, match := range matches {
+		start, end := match[2*group], match[2*group+1]
+		if start == -1 {
+			// the group did not take part in this match
+			continue
+		}
+
+		result = append(result, currentContent[last:start]...)
+		result = append(result, newVersion...)
+		last = end
+		updated++
+	}
+
+	if updated == 0 {
+		return nil, fmt.Errorf("%w: pattern %s does not match", ErrNothingToUpdate, u.cfg.Pattern)
+	}
+
+	return append(result, currentContent[last:]...), nil
+}
@@ -0,0 +1,70 @@
+package update
+
+import (
+	"fmt"
+
+	"github.com/rikotsev/easy-release/internal/config"
+)
+
+func (suite *UpdateTestSuite) TestRegexUpdate() {
+	tests := []struct {
+		oldContent string
+		newVersion string
+		newContent string
+		cfg        config.Update
+	}{
+		{
+			oldContent: "package main\n\nconst Version = \"1.2.3\"\n",
+			newVersion: "1.3.0",
+			newContent: "package main\n\nconst Version = \"1.3.0\"\n",
+			cfg: config.Update{
+				Kind:    config.UpdateKindRegex,
+				Pattern: `const Version = "(.+)"`,
+			},
+		},
+		{
+			oldContent: "![version](https://img.shields.io/badge/version-1.2.3-blue)\n![version](https://img.shields.io/badge/version-1.2.3-green)\n",
+			newVersion: "2.0.0",
+			newContent: "![version](https://img.shields.io/badge/version-2.0.0-blue)\n![version](https://img.shields.io/badge/version-2.0.0-green)\n",
+			cfg: config.Update{
+				Kind:    config.UpdateKindRegex,
+				Pattern: `badge/(version)-(\d+\.\d+\.\d+)-`,
+				Group:   2,
+			},
+		},
+	}
+
+	for idx, testCase := range tests {
+		suite.Run(fmt.Sprintf("testing regex update: [%d]", idx), func() {
+			updater := updateRegex{
+				cfg: testCase.cfg,
+			}
+
+			actual, err := updater.Run([]byte(testCase.oldContent), testCase.newVersion)
+			suite.NoError(err)
+
+			suite.Equal(testCase.newContent, string(actual))
+		})
+	}
+
+	suite.Run("nothing matches", func() {
+		updater := updateRegex{cfg: config.Update{Pattern: `version: (.+)`}}
+
+		_, err := updater.Run([]byte("name: app\n"), "1.0.0")
+		suite.ErrorIs(err, ErrNothingToUpdate)
+	})
+
+	suite.Run("the group does not exist", func() {
+		updater := updateRegex{cfg: config.Update{Pattern: `version: .+`}}
+
+		_, err := updater.Run([]byte("version: 1.0.0\n"), "1.1.0")
+		suite.Error(err)
+	})
+
+	suite.Run("the pattern is not valid", func() {
+		updater := updateRegex{cfg: config.Update{Pattern: `(`}}
+
+		_, err := updater.Run([]byte("version: 1.0.0\n"), "1.1.0")
+		suite.Error(err)
+	})
+}
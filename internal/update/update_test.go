@@ -1,6 +1,8 @@
 package update
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/rikotsev/easy-release/internal/config"
@@ -12,9 +14,9 @@ type UpdateTestSuite struct {
 }
 
 func (suite *UpdateTestSuite) TestInvalidKindError() {
-	_, _, err := Execute("1.0.0", config.Update{
+	_, err := Execute("1.0.0", []config.Update{{
 		Kind: "Random",
-	})
+	}})
 
 	suite.ErrorIs(ErrNotSupportedUpdateKind, err)
 }
@@ -47,6 +49,61 @@ func (suite *UpdateTestSuite) TestValidKind() {
 		})
 		suite.Require().NoError(err)
 	})
+
+	suite.Run("regex updater exists", func() {
+		_, err := getUpdater(config.Update{
+			Kind: config.UpdateKindRegex,
+		})
+		suite.Require().NoError(err)
+	})
+
+	suite.Run("marker updater exists", func() {
+		_, err := getUpdater(config.Update{
+			Kind: config.UpdateKindMarker,
+		})
+		suite.Require().NoError(err)
+	})
+
+	suite.Run("gradle updater exists", func() {
+		_, err := getUpdater(config.Update{
+			Kind: config.UpdateKindGradle,
+		})
+		suite.Require().NoError(err)
+	})
+
+	suite.Run("helm chart updater exists", func() {
+		_, err := getUpdater(config.Update{
+			Kind: config.UpdateKindHelmChart,
+		})
+		suite.Require().NoError(err)
+	})
+
+	suite.Run("dotnet updater exists", func() {
+		_, err := getUpdater(config.Update{
+			Kind: config.UpdateKindDotnet,
+		})
+		suite.Require().NoError(err)
+	})
+}
+
+func (suite *UpdateTestSuite) TestUpdatesOfTheSameFileAreChained() {
+	dir := suite.T().TempDir()
+	chartPath := filepath.Join(dir, "Chart.yaml")
+	valuesPath := filepath.Join(dir, "values.yaml")
+	suite.Require().NoError(os.WriteFile(chartPath, []byte("version: 0.1.0\nappVersion: 0.1.0\n"), 0644))
+	suite.Require().NoError(os.WriteFile(valuesPath, []byte("tag: 0.1.0 # x-easy-release-version\n"), 0644))
+
+	updatedFiles, err := Execute("1.0.0", []config.Update{
+		{FilePath: chartPath, Kind: config.UpdateKindRegex, Pattern: `(?m)^version: (.+)$`},
+		{FilePath: valuesPath, Kind: config.UpdateKindMarker},
+		{FilePath: chartPath, Kind: config.UpdateKindRegex, Pattern: `(?m)^appVersion: (.+)$`},
+	})
+
+	suite.Require().NoError(err)
+	suite.Equal([]UpdatedFile{
+		{Path: chartPath, Content: []byte("version: 1.0.0\nappVersion: 1.0.0\n")},
+		{Path: valuesPath, Content: []byte("tag: 1.0.0 # x-easy-release-version\n")},
+	}, updatedFiles)
 }
 
 func TestUpdateTestSuite(t *testing.T) {
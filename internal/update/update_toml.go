@@ -2,24 +2,139 @@ package update
 
 import (
 	"fmt"
-	"github.com/rikotsev/easy-release/internal/config"
+	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/rikotsev/easy-release/internal/config"
 )
 
+// tomlStringValueRegex matches the string value of a key and what follows it - `"1.0.0" # a comment`.
+var tomlStringValueRegex = regexp.MustCompile(`(?s)^(\s*)("[^"\\]*(?:\\.[^"\\]*)*"|'[^']*')(.*)$`)
+
 type updateToml struct {
 	cfg config.Update
 }
 
+// Run replaces the string value of the key path - `package.version` or `tool.poetry.version` - keeping the quotes,
+// the comments and the rest of the file as they are. A numeric path is the index of the line to overwrite with
+// `version = "..."` as before key paths were supported.
 func (t *updateToml) Run(currentContent []byte, newVersion string) ([]byte, error) {
+	if lineNumber, err := strconv.Atoi(t.cfg.TomlPath); err == nil {
+		lines := strings.Split(string(currentContent), "\n")
+		if lineNumber < 0 || lineNumber >= len(lines) {
+			return nil, fmt.Errorf("%w: line %d is out of the file", ErrNothingToUpdate, lineNumber)
+		}
+
+		lines[lineNumber] = fmt.Sprintf("version = \"%s\"", newVersion)
+
+		return []byte(strings.Join(lines, "\n")), nil
+	}
+
+	lines := strings.SplitAfter(string(currentContent), "\n")
+	idx, key, value, found := tomlKeyLine(lines, t.cfg.TomlPath)
+	if !found {
+		return nil, fmt.Errorf("%w: key %s is not in the file", ErrNothingToUpdate, t.cfg.TomlPath)
+	}
+
+	matches := tomlStringValueRegex.FindStringSubmatch(value)
+	if matches == nil {
+		return nil, fmt.Errorf("the value of %s on line %d is not a string", t.cfg.TomlPath, idx+1)
+	}
+
+	quote := matches[2][:1]
+	lines[idx] = key + "=" + matches[1] + quote + newVersion + quote + matches[3]
+
+	return []byte(strings.Join(lines, "")), nil
+}
+
+// tomlHasString reports whether the key path has a string value in the content.
+func tomlHasString(content []byte, keyPath string) bool {
+	_, _, value, found := tomlKeyLine(strings.SplitAfter(string(content), "\n"), keyPath)
+
+	return found && tomlStringValueRegex.MatchString(value)
+}
 
-	lines := strings.Split(string(currentContent), "\n")
-	lineNumber, err := strconv.Atoi(t.cfg.TomlPath)
-	if err != nil {
-		return nil, err
+// tomlKeyLine finds the line assigning the key path and returns its index with what is before and after the `=`.
+func tomlKeyLine(lines []string, keyPath string) (int, string, string, bool) {
+	target := strings.Join(tomlKeyPath(keyPath), ".")
+	table := []string{}
+	multiline := ""
+
+	for idx, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		// the content of multi-line strings can look like anything, so it is skipped until the closing quotes
+		if multiline != "" {
+			if strings.Count(trimmed, multiline)%2 == 1 {
+				multiline = ""
+			}
+			continue
+		}
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "[") {
+			table = tomlTable(trimmed)
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+
+		for _, quotes := range []string{`"""`, `'''`} {
+			if strings.Count(value, quotes)%2 == 1 {
+				multiline = quotes
+			}
+		}
+
+		if strings.Join(append(append([]string{}, table...), tomlKeyPath(key)...), ".") == target {
+			return idx, key, value, true
+		}
 	}
 
-	lines[lineNumber] = fmt.Sprintf("version = \"%s\"", newVersion)
+	return -1, "", "", false
+}
+
+// tomlTable returns the key path of a `[tool.poetry]` or `[[bin]]` header. Array tables never match a key path.
+func tomlTable(header string) []string {
+	if strings.HasPrefix(header, "[[") {
+		return []string{"[["}
+	}
+
+	end := strings.LastIndex(header, "]")
+	if end == -1 {
+		return []string{}
+	}
+
+	return tomlKeyPath(header[1:end])
+}
+
+// tomlKeyPath splits a dotted key into its parts - `tool."poetry".version` into `tool`, `poetry` and `version`.
+func tomlKeyPath(key string) []string {
+	result := []string{}
+	var part strings.Builder
+	quote := rune(0)
+
+	for _, char := range key {
+		switch {
+		case quote != 0 && char == quote:
+			quote = 0
+		case quote != 0:
+			part.WriteRune(char)
+		case char == '"' || char == '\'':
+			quote = char
+		case char == '.':
+			result = append(result, strings.TrimSpace(part.String()))
+			part.Reset()
+		default:
+			part.WriteRune(char)
+		}
+	}
 
-	return []byte(strings.Join(lines, "\n")), nil
+	return append(result, strings.TrimSpace(part.String()))
 }
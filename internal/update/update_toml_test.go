@@ -190,6 +190,71 @@ build-backend = "poetry.core.masonry.api"
 				TomlPath: "2",
 			},
 		},
+		{
+			oldContent: `[tool.poetry]
+name = "app"
+version   =   "0.1.0"   # keep in sync with the image
+
+[project]
+version = "0.1.0"
+`,
+			newVersion: "1.0.0",
+			newContent: `[tool.poetry]
+name = "app"
+version   =   "1.0.0"   # keep in sync with the image
+
+[project]
+version = "0.1.0"
+`,
+			cfg: config.Update{
+				Kind:     config.UpdateKindToml,
+				TomlPath: "tool.poetry.version",
+			},
+		},
+		{
+			oldContent: `[package]
+name = "app"
+description = """
+[workspace]
+version = "9.9.9"
+"""
+version = '0.1.0'
+
+[[bin]]
+version = "0.1.0"
+`,
+			newVersion: "0.2.0",
+			newContent: `[package]
+name = "app"
+description = """
+[workspace]
+version = "9.9.9"
+"""
+version = '0.2.0'
+
+[[bin]]
+version = "0.1.0"
+`,
+			cfg: config.Update{
+				Kind:     config.UpdateKindToml,
+				TomlPath: "package.version",
+			},
+		},
+		{
+			oldContent: `[tool]
+poetry.version = "0.1.0"
+"poetry".name = "app"
+`,
+			newVersion: "0.1.1",
+			newContent: `[tool]
+poetry.version = "0.1.1"
+"poetry".name = "app"
+`,
+			cfg: config.Update{
+				Kind:     config.UpdateKindToml,
+				TomlPath: "tool.poetry.version",
+			},
+		},
 	}
 
 	for idx, testCase := range tests {
@@ -204,4 +269,25 @@ build-backend = "poetry.core.masonry.api"
 			suite.Equal(testCase.newContent, string(actual))
 		})
 	}
+
+	suite.Run("the key is missing", func() {
+		updater := updateToml{cfg: config.Update{TomlPath: "project.version"}}
+
+		_, err := updater.Run([]byte("[tool.poetry]\nversion = \"0.1.0\"\n"), "1.0.0")
+		suite.ErrorIs(err, ErrNothingToUpdate)
+	})
+
+	suite.Run("the value is not a string", func() {
+		updater := updateToml{cfg: config.Update{TomlPath: "package.version"}}
+
+		_, err := updater.Run([]byte("[package]\nversion = 1\n"), "1.0.0")
+		suite.Error(err)
+	})
+
+	suite.Run("the line is out of the file", func() {
+		updater := updateToml{cfg: config.Update{TomlPath: "10"}}
+
+		_, err := updater.Run([]byte("version = \"0.1.0\"\n"), "1.0.0")
+		suite.ErrorIs(err, ErrNothingToUpdate)
+	})
 }
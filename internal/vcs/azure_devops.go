@@ -3,6 +3,7 @@ package vcs
 import (
 	"context"
 	"fmt"
+	"net/url"
 
 	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
 	devopsgit "github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
@@ -257,3 +258,42 @@ func (api *azureDevopsApiImpl) GetPRTitle(ctx context.Context, prId int) (string
 
 	return "", nil
 }
+
+func (api *azureDevopsApiImpl) GetPRLabels(ctx context.Context, prId int) ([]string, error) {
+	resp, err := api.client.GetPullRequestLabels(ctx, devopsgit.GetPullRequestLabelsArgs{
+		RepositoryId:  &api.opts.Repo,
+		PullRequestId: util.Int(prId),
+		Project:       &api.opts.Project,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get labels of PR with id: %d with: %w", prId, err)
+	}
+
+	result := []string{}
+	if resp == nil {
+		return result, nil
+	}
+
+	for _, label := range *resp {
+		if label.Name != nil && (label.Active == nil || *label.Active) {
+			result = append(result, *label.Name)
+		}
+	}
+
+	return result, nil
+}
+
+func (api *azureDevopsApiImpl) WebLinks() WebLinks {
+	return AzureDevopsWebLinks(api.opts.Org, api.opts.Project, api.opts.Repo)
+}
+
+// AzureDevopsWebLinks are the links of an Azure DevOps repository. They need no api call, so they are known without a
+// token.
+func AzureDevopsWebLinks(org string, project string, repo string) WebLinks {
+	repoUrl := fmt.Sprintf("https://dev.azure.com/%s/%s/_git/%s", url.PathEscape(org), url.PathEscape(project), url.PathEscape(repo))
+
+	return WebLinks{
+		Compare: repoUrl + "/branchCompare?baseVersion=GT{from}&targetVersion=GT{to}",
+		Commit:  repoUrl + "/commit/{sha}",
+	}
+}
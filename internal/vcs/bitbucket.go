@@ -0,0 +1,459 @@
+package vcs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/rikotsev/easy-release/internal/config"
+)
+
+const bitbucketCloudBaseUrl = "https://api.bitbucket.org/2.0"
+
+// NewBitbucket creates an api for Bitbucket Cloud or - when ApiOpts.BaseUrl is set - for a Bitbucket Data Center instance.
+// ApiOpts.Project is the workspace (Cloud) or the project key (Data Center) and ApiOpts.Repo is the repository slug.
+// The token can be an access token or `username:app-password`.
+func NewBitbucket(cfg *config.Config, opts ApiOpts) (Api, error) {
+	headers := map[string]string{
+		"Authorization": authorizationHeader(opts.Token),
+	}
+
+	if opts.BaseUrl == "" {
+		return &bitbucketCloudApiImpl{
+			cfg:    cfg,
+			opts:   opts,
+			client: newRestClient(bitbucketCloudBaseUrl, headers),
+		}, nil
+	}
+
+	return &bitbucketDataCenterApiImpl{
+		cfg:    cfg,
+		opts:   opts,
+		client: newRestClient(opts.BaseUrl, headers),
+	}, nil
+}
+
+type bitbucketCloudApiImpl struct {
+	cfg    *config.Config
+	opts   ApiOpts
+	client *restClient
+}
+
+var _ Api = &bitbucketCloudApiImpl{}
+var _ RefPlanner = &bitbucketCloudApiImpl{}
+
+type bitbucketCloudCommit struct {
+	Hash    string `json:"hash"`
+	Message string `json:"message,omitempty"`
+}
+
+type bitbucketCloudRef struct {
+	Name    string               `json:"name"`
+	Target  bitbucketCloudCommit `json:"target"`
+	Message string               `json:"message,omitempty"`
+}
+
+type bitbucketCloudBranch struct {
+	Branch struct {
+		Name string `json:"name"`
+	} `json:"branch"`
+}
+
+type bitbucketCloudPullRequest struct {
+	Id          int                   `json:"id,omitempty"`
+	Title       string                `json:"title,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Source      *bitbucketCloudBranch `json:"source,omitempty"`
+	Destination *bitbucketCloudBranch `json:"destination,omitempty"`
+}
+
+type bitbucketCloudPage[T interface{}] struct {
+	Values []T `json:"values"`
+}
+
+func (b *bitbucketCloudApiImpl) repoPath(format string, args ...interface{}) string {
+	return fmt.Sprintf("/repositories/%s/%s", url.PathEscape(b.opts.Project), url.PathEscape(b.opts.Repo)) + fmt.Sprintf(format, args...)
+}
+
+func newBitbucketCloudBranch(name string) *bitbucketCloudBranch {
+	branch := bitbucketCloudBranch{}
+	branch.Branch.Name = name
+
+	return &branch
+}
+
+func (b *bitbucketCloudApiImpl) GetLastRef(ctx context.Context, branch string) (string, error) {
+	var resp bitbucketCloudRef
+	status, err := b.client.do(ctx, http.MethodGet, b.repoPath("/refs/branches/%s", url.PathEscape(branch)), nil, &resp)
+
+	if status == http.StatusNotFound {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to get last ref for branch: %s with err: %w", branch, err)
+	}
+
+	return resp.Target.Hash, nil
+}
+
+// UpdateRef creates the branch on newSha when it does not exist. Bitbucket Cloud cannot move an existing branch and
+// deleting it declines its pull request, so an existing branch is kept and its last sha is returned - the release
+// commit is pushed on top of it. The base branch is not merged into it, so an open release pull request stays on the
+// base it was created from.
+func (b *bitbucketCloudApiImpl) UpdateRef(ctx context.Context, branch string, newSha string, oldSha string) (string, error) {
+	currentSha, err := b.GetLastRef(ctx, branch)
+	if err != nil {
+		return "", err
+	}
+
+	if currentSha != "" {
+		return currentSha, nil
+	}
+
+	_, err = b.client.do(ctx, http.MethodPost, b.repoPath("/refs/branches"), bitbucketCloudRef{
+		Name:   branch,
+		Target: bitbucketCloudCommit{Hash: newSha},
+	}, nil)
+	if err != nil {
+		return "", fmt.Errorf("bitbucket: %w: %v", ErrCannotCreateBranch, err)
+	}
+
+	return newSha, nil
+}
+
+// PlanRef reports that an existing branch is kept on its last sha.
+func (b *bitbucketCloudApiImpl) PlanRef(ctx context.Context, branch string, newSha string, oldSha string) (PlannedRef, error) {
+	currentSha, err := b.GetLastRef(ctx, branch)
+	if err != nil {
+		return PlannedRef{}, err
+	}
+
+	if currentSha != "" {
+		return PlannedRef{Sha: currentSha}, nil
+	}
+
+	return PlannedRef{Action: ActionUpdateRef, Sha: newSha}, nil
+}
+
+func (b *bitbucketCloudApiImpl) PushCommit(ctx context.Context, branch string, lastSha string, message string, changes []RemoteChange) error {
+	fields := map[string]string{
+		"message": message,
+		"branch":  branch,
+		"parents": lastSha,
+	}
+
+	for _, change := range changes {
+		fields[change.Path] = change.Content
+	}
+
+	if _, err := b.client.doMultipart(ctx, http.MethodPost, b.repoPath("/src"), fields, nil); err != nil {
+		return fmt.Errorf("failed to push bitbucket commit: %w", err)
+	}
+
+	return nil
+}
+
+func (b *bitbucketCloudApiImpl) GetPR(ctx context.Context, toBranch string, fromBranch string) (int, error) {
+	query := url.Values{}
+	query.Set("q", fmt.Sprintf(`source.branch.name="%s" AND destination.branch.name="%s" AND state="OPEN"`, fromBranch, toBranch))
+
+	var page bitbucketCloudPage[bitbucketCloudPullRequest]
+	_, err := b.client.do(ctx, http.MethodGet, b.repoPath("/pullrequests?%s", query.Encode()), nil, &page)
+	if err != nil {
+		return -1, fmt.Errorf("failed to retrieve pull requests from branch: %s to branch: %s with err: %w", fromBranch, toBranch, err)
+	}
+
+	if len(page.Values) == 0 {
+		return -1, nil
+	}
+
+	return page.Values[0].Id, nil
+}
+
+func (b *bitbucketCloudApiImpl) CreatePR(ctx context.Context, toBranch string, fromBranch string, title string, description string) (int, error) {
+	var resp bitbucketCloudPullRequest
+	_, err := b.client.do(ctx, http.MethodPost, b.repoPath("/pullrequests"), bitbucketCloudPullRequest{
+		Title:       title,
+		Description: description,
+		Source:      newBitbucketCloudBranch(fromBranch),
+		Destination: newBitbucketCloudBranch(toBranch),
+	}, &resp)
+	if err != nil {
+		return -1, fmt.Errorf("bitbucket: %w: %v", ErrCannotCreatePullRequest, err)
+	}
+
+	return resp.Id, nil
+}
+
+func (b *bitbucketCloudApiImpl) UpdatePR(ctx context.Context, prId int, title string, description string) (int, error) {
+	var resp bitbucketCloudPullRequest
+	_, err := b.client.do(ctx, http.MethodPut, b.repoPath("/pullrequests/%d", prId), bitbucketCloudPullRequest{
+		Title:       title,
+		Description: description,
+	}, &resp)
+	if err != nil {
+		return -1, fmt.Errorf("bitbucket: %w: %v", ErrCannotUpdatePullRequest, err)
+	}
+
+	return resp.Id, nil
+}
+
+func (b *bitbucketCloudApiImpl) GetLastCommitMessage(ctx context.Context, branch string) (string, string, error) {
+	var page bitbucketCloudPage[bitbucketCloudCommit]
+	_, err := b.client.do(ctx, http.MethodGet, b.repoPath("/commits/%s?pagelen=1", url.PathEscape(branch)), nil, &page)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get last commit msg: %w", err)
+	}
+
+	if len(page.Values) == 0 {
+		return "", "", nil
+	}
+
+	return page.Values[0].Hash, page.Values[0].Message, nil
+}
+
+func (b *bitbucketCloudApiImpl) CreateAnnotatedTag(ctx context.Context, sha string, version string) error {
+	_, err := b.client.do(ctx, http.MethodPost, b.repoPath("/refs/tags"), bitbucketCloudRef{
+		Name:    version,
+		Target:  bitbucketCloudCommit{Hash: sha},
+		Message: version,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("cannot create tag %v in bitbucket: %w", version, err)
+	}
+
+	return nil
+}
+
+func (b *bitbucketCloudApiImpl) GetPRTitle(ctx context.Context, prId int) (string, error) {
+	var resp bitbucketCloudPullRequest
+	_, err := b.client.do(ctx, http.MethodGet, b.repoPath("/pullrequests/%d", prId), nil, &resp)
+	if err != nil {
+		return "", fmt.Errorf("could not get PR with id: %d with error: %w", prId, err)
+	}
+
+	return resp.Title, nil
+}
+
+type bitbucketDataCenterApiImpl struct {
+	cfg    *config.Config
+	opts   ApiOpts
+	client *restClient
+}
+
+var _ Api = &bitbucketDataCenterApiImpl{}
+var _ RefPlanner = &bitbucketDataCenterApiImpl{}
+
+type bitbucketDataCenterCommit struct {
+	Id      string `json:"id"`
+	Message string `json:"message"`
+}
+
+type bitbucketDataCenterBranch struct {
+	Id           string `json:"id"`
+	DisplayId    string `json:"displayId"`
+	LatestCommit string `json:"latestCommit"`
+}
+
+type bitbucketDataCenterRef struct {
+	Id string `json:"id"`
+}
+
+type bitbucketDataCenterPullRequest struct {
+	Id          int                     `json:"id,omitempty"`
+	Version     int                     `json:"version"`
+	Title       string                  `json:"title,omitempty"`
+	Description string                  `json:"description,omitempty"`
+	FromRef     *bitbucketDataCenterRef `json:"fromRef,omitempty"`
+	ToRef       *bitbucketDataCenterRef `json:"toRef,omitempty"`
+}
+
+type bitbucketDataCenterPage[T interface{}] struct {
+	Values []T `json:"values"`
+}
+
+func (b *bitbucketDataCenterApiImpl) repoPath(api string, format string, args ...interface{}) string {
+	return fmt.Sprintf("/rest/%s/projects/%s/repos/%s", api, url.PathEscape(b.opts.Project), url.PathEscape(b.opts.Repo)) + fmt.Sprintf(format, args...)
+}
+
+func (b *bitbucketDataCenterApiImpl) GetLastRef(ctx context.Context, branch string) (string, error) {
+	query := url.Values{}
+	query.Set("filterText", branch)
+
+	var page bitbucketDataCenterPage[bitbucketDataCenterBranch]
+	_, err := b.client.do(ctx, http.MethodGet, b.repoPath("api/1.0", "/branches?%s", query.Encode()), nil, &page)
+	if err != nil {
+		return "", fmt.Errorf("failed to get last ref for branch: %s with err: %w", branch, err)
+	}
+
+	for _, candidate := range page.Values {
+		if candidate.DisplayId == branch {
+			return candidate.LatestCommit, nil
+		}
+	}
+
+	return "", nil
+}
+
+// UpdateRef creates the branch on newSha through the branch-utils api when it does not exist. There is no force update
+// of a ref and deleting the branch declines its pull request, so an existing branch is kept and its last sha is
+// returned - the release commits are pushed on top of it. The base branch is not merged into it, so an open release
+// pull request stays on the base it was created from.
+func (b *bitbucketDataCenterApiImpl) UpdateRef(ctx context.Context, branch string, newSha string, oldSha string) (string, error) {
+	currentSha, err := b.GetLastRef(ctx, branch)
+	if err != nil {
+		return "", err
+	}
+
+	if currentSha != "" {
+		return currentSha, nil
+	}
+
+	_, err = b.client.do(ctx, http.MethodPost, b.repoPath("branch-utils/1.0", "/branches"), map[string]string{
+		"name":       branch,
+		"startPoint": newSha,
+	}, nil)
+	if err != nil {
+		return "", fmt.Errorf("bitbucket: %w: %v", ErrCannotCreateBranch, err)
+	}
+
+	return newSha, nil
+}
+
+// PlanRef reports that an existing branch is kept on its last sha.
+func (b *bitbucketDataCenterApiImpl) PlanRef(ctx context.Context, branch string, newSha string, oldSha string) (PlannedRef, error) {
+	currentSha, err := b.GetLastRef(ctx, branch)
+	if err != nil {
+		return PlannedRef{}, err
+	}
+
+	if currentSha != "" {
+		return PlannedRef{Sha: currentSha}, nil
+	}
+
+	return PlannedRef{Action: ActionUpdateRef, Sha: newSha}, nil
+}
+
+// PushCommit uses the file edit api which accepts a single file per commit - there is no api for a commit of several
+// files, so the commits are chained one after another and every one of them carries the release message.
+func (b *bitbucketDataCenterApiImpl) PushCommit(ctx context.Context, branch string, lastSha string, message string, changes []RemoteChange) error {
+	sourceCommitId := lastSha
+
+	for _, change := range changes {
+		var resp bitbucketDataCenterCommit
+		_, err := b.client.doMultipart(ctx, http.MethodPut, b.repoPath("api/1.0", "/browse/%s", change.Path), map[string]string{
+			"content":        change.Content,
+			"message":        message,
+			"branch":         branch,
+			"sourceCommitId": sourceCommitId,
+		}, &resp)
+		if err != nil {
+			return fmt.Errorf("failed to push bitbucket commit for: %s with: %w", change.Path, err)
+		}
+
+		sourceCommitId = resp.Id
+	}
+
+	return nil
+}
+
+func (b *bitbucketDataCenterApiImpl) GetPR(ctx context.Context, toBranch string, fromBranch string) (int, error) {
+	query := url.Values{}
+	query.Set("direction", "OUTGOING")
+	query.Set("at", "refs/heads/"+fromBranch)
+	query.Set("state", "OPEN")
+
+	var page bitbucketDataCenterPage[bitbucketDataCenterPullRequest]
+	_, err := b.client.do(ctx, http.MethodGet, b.repoPath("api/1.0", "/pull-requests?%s", query.Encode()), nil, &page)
+	if err != nil {
+		return -1, fmt.Errorf("failed to retrieve pull requests from branch: %s to branch: %s with err: %w", fromBranch, toBranch, err)
+	}
+
+	for _, pr := range page.Values {
+		if pr.ToRef != nil && pr.ToRef.Id == "refs/heads/"+toBranch {
+			return pr.Id, nil
+		}
+	}
+
+	return -1, nil
+}
+
+func (b *bitbucketDataCenterApiImpl) CreatePR(ctx context.Context, toBranch string, fromBranch string, title string, description string) (int, error) {
+	var resp bitbucketDataCenterPullRequest
+	_, err := b.client.do(ctx, http.MethodPost, b.repoPath("api/1.0", "/pull-requests"), bitbucketDataCenterPullRequest{
+		Title:       title,
+		Description: description,
+		FromRef:     &bitbucketDataCenterRef{Id: "refs/heads/" + fromBranch},
+		ToRef:       &bitbucketDataCenterRef{Id: "refs/heads/" + toBranch},
+	}, &resp)
+	if err != nil {
+		return -1, fmt.Errorf("bitbucket: %w: %v", ErrCannotCreatePullRequest, err)
+	}
+
+	return resp.Id, nil
+}
+
+// UpdatePR needs the current version of the pull request for optimistic locking, so it is fetched first.
+func (b *bitbucketDataCenterApiImpl) UpdatePR(ctx context.Context, prId int, title string, description string) (int, error) {
+	var current bitbucketDataCenterPullRequest
+	_, err := b.client.do(ctx, http.MethodGet, b.repoPath("api/1.0", "/pull-requests/%d", prId), nil, &current)
+	if err != nil {
+		return -1, fmt.Errorf("bitbucket: %w: %v", ErrCannotUpdatePullRequest, err)
+	}
+
+	var resp bitbucketDataCenterPullRequest
+	_, err = b.client.do(ctx, http.MethodPut, b.repoPath("api/1.0", "/pull-requests/%d", prId), bitbucketDataCenterPullRequest{
+		Version:     current.Version,
+		Title:       title,
+		Description: description,
+	}, &resp)
+	if err != nil {
+		return -1, fmt.Errorf("bitbucket: %w: %v", ErrCannotUpdatePullRequest, err)
+	}
+
+	return resp.Id, nil
+}
+
+func (b *bitbucketDataCenterApiImpl) GetLastCommitMessage(ctx context.Context, branch string) (string, string, error) {
+	query := url.Values{}
+	query.Set("until", "refs/heads/"+branch)
+	query.Set("limit", "1")
+
+	var page bitbucketDataCenterPage[bitbucketDataCenterCommit]
+	_, err := b.client.do(ctx, http.MethodGet, b.repoPath("api/1.0", "/commits?%s", query.Encode()), nil, &page)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get last commit msg: %w", err)
+	}
+
+	if len(page.Values) == 0 {
+		return "", "", nil
+	}
+
+	return page.Values[0].Id, page.Values[0].Message, nil
+}
+
+func (b *bitbucketDataCenterApiImpl) CreateAnnotatedTag(ctx context.Context, sha string, version string) error {
+	// providing a message is what makes the tag annotated
+	_, err := b.client.do(ctx, http.MethodPost, b.repoPath("api/1.0", "/tags"), map[string]string{
+		"name":       version,
+		"startPoint": sha,
+		"message":    version,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("cannot create tag %v in bitbucket: %w", version, err)
+	}
+
+	return nil
+}
+
+func (b *bitbucketDataCenterApiImpl) GetPRTitle(ctx context.Context, prId int) (string, error) {
+	var resp bitbucketDataCenterPullRequest
+	_, err := b.client.do(ctx, http.MethodGet, b.repoPath("api/1.0", "/pull-requests/%d", prId), nil, &resp)
+	if err != nil {
+		return "", fmt.Errorf("could not get PR with id: %d with error: %w", prId, err)
+	}
+
+	return resp.Title, nil
+}
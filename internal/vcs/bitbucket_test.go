@@ -0,0 +1,236 @@
+package vcs
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/rikotsev/easy-release/internal/config"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	bitbucketCloudRepo      = "/repositories/workspace/repo"
+	bitbucketDataCenterRepo = "/rest/api/1.0/projects/PRJ/repos/repo"
+	bitbucketBranchUtils    = "/rest/branch-utils/1.0/projects/PRJ/repos/repo"
+)
+
+type BitbucketTestSuite struct {
+	suite.Suite
+	ctx  context.Context
+	stub *apiStub
+}
+
+func (s *BitbucketTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.stub = newApiStub()
+}
+
+func (s *BitbucketTestSuite) TearDownTest() {
+	s.stub.close()
+}
+
+func (s *BitbucketTestSuite) cloudApi() Api {
+	api, err := NewBitbucket(config.Default(), ApiOpts{
+		Token:   "user:app-password",
+		Project: "workspace",
+		Repo:    "repo",
+	})
+	s.Require().NoError(err)
+	api.(*bitbucketCloudApiImpl).client.baseUrl = s.stub.server.URL
+
+	return api
+}
+
+func (s *BitbucketTestSuite) dataCenterApi() Api {
+	api, err := NewBitbucket(config.Default(), ApiOpts{
+		Token:   "secret",
+		Project: "PRJ",
+		Repo:    "repo",
+		BaseUrl: s.stub.server.URL,
+	})
+	s.Require().NoError(err)
+
+	return api
+}
+
+func (s *BitbucketTestSuite) TestCloudFlavourIsTheDefault() {
+	api, err := NewBitbucket(config.Default(), ApiOpts{Token: "secret"})
+	s.Require().NoError(err)
+
+	cloud, ok := api.(*bitbucketCloudApiImpl)
+	s.Require().True(ok)
+	s.Equal(bitbucketCloudBaseUrl, cloud.client.baseUrl)
+	s.Equal("Bearer secret", cloud.client.headers["Authorization"])
+}
+
+func (s *BitbucketTestSuite) TestCloudReleaseBranch() {
+	s.stub.handle("GET "+bitbucketCloudRepo+"/refs/branches/easy-release--master", http.StatusNotFound, `{}`)
+	s.stub.handle("POST "+bitbucketCloudRepo+"/refs/branches", http.StatusCreated, `{}`)
+	s.stub.handle("POST "+bitbucketCloudRepo+"/src", http.StatusCreated, ``)
+	api := s.cloudApi()
+
+	sha, err := api.UpdateRef(s.ctx, "easy-release--master", "new", "0000")
+	s.Require().NoError(err)
+	s.Equal("new", sha)
+	s.Equal("new", s.stub.lastRequest("POST " + bitbucketCloudRepo + "/refs/branches")["target"].(map[string]interface{})["hash"])
+
+	err = api.PushCommit(s.ctx, "easy-release--master", "new", "chore(release): 1.0.0", []RemoteChange{
+		{Path: "CHANGELOG.md", Content: "## 1.0.0"},
+	})
+	s.Require().NoError(err)
+
+	form := s.stub.lastRequest("POST " + bitbucketCloudRepo + "/src")
+	s.Equal("## 1.0.0", form["CHANGELOG.md"])
+	s.Equal("new", form["parents"])
+	s.Equal("easy-release--master", form["branch"])
+	s.Contains(s.stub.headers["POST "+bitbucketCloudRepo+"/src"][0].Get("Authorization"), "Basic ")
+}
+
+func (s *BitbucketTestSuite) TestCloudPullRequestSurvivesTheNextRun() {
+	s.stub.handle("GET "+bitbucketCloudRepo+"/refs/branches/easy-release--master", http.StatusOK, `{"name":"easy-release--master","target":{"hash":"release"}}`)
+	s.stub.handle("DELETE "+bitbucketCloudRepo+"/refs/branches/easy-release--master", http.StatusNoContent, ``)
+	s.stub.handle("POST "+bitbucketCloudRepo+"/refs/branches", http.StatusCreated, `{}`)
+	s.stub.handle("POST "+bitbucketCloudRepo+"/src", http.StatusCreated, ``)
+	s.stub.handle("GET "+bitbucketCloudRepo+"/pullrequests", http.StatusOK, `{"values":[{"id":3}]}`)
+	s.stub.handle("PUT "+bitbucketCloudRepo+"/pullrequests/3", http.StatusOK, `{"id":3}`)
+	api := s.cloudApi()
+
+	sha, err := api.UpdateRef(s.ctx, "easy-release--master", "base", "release")
+	s.Require().NoError(err)
+	s.Equal("release", sha, "the existing branch is kept")
+
+	s.Require().NoError(api.PushCommit(s.ctx, "easy-release--master", sha, "chore(release): 1.1.0", []RemoteChange{
+		{Path: "CHANGELOG.md", Content: "## 1.1.0"},
+	}))
+	s.Equal("release", s.stub.lastRequest("POST " + bitbucketCloudRepo + "/src")["parents"])
+
+	id, err := api.GetPR(s.ctx, "master", "easy-release--master")
+	s.Require().NoError(err)
+	s.Equal(3, id)
+	id, err = api.UpdatePR(s.ctx, id, "chore(release): 1.1.0", "body")
+	s.Require().NoError(err)
+	s.Equal(3, id)
+
+	s.Zero(s.stub.calls("DELETE "+bitbucketCloudRepo+"/refs/branches/easy-release--master"), "deleting the branch declines the pull request")
+	s.Zero(s.stub.calls("POST " + bitbucketCloudRepo + "/refs/branches"))
+}
+
+func (s *BitbucketTestSuite) TestDryRunKeepsTheExistingBranch() {
+	s.stub.handle("GET "+bitbucketCloudRepo+"/refs/branches/easy-release--master", http.StatusOK, `{"name":"easy-release--master","target":{"hash":"release"}}`)
+	s.stub.handle("GET "+bitbucketDataCenterRepo+"/branches", http.StatusOK, `{"values":[{"displayId":"easy-release--master","latestCommit":"release"}]}`)
+
+	for _, api := range []Api{s.cloudApi(), s.dataCenterApi()} {
+		recorder := NewRecording(api)
+
+		sha, err := recorder.UpdateRef(s.ctx, "easy-release--master", "base", "release")
+		s.Require().NoError(err)
+		s.Equal("release", sha, "the next commit goes on top of the kept branch")
+		s.Empty(recorder.Plan().Actions)
+	}
+
+	s.stub.handle("GET "+bitbucketCloudRepo+"/refs/branches/easy-release--develop", http.StatusNotFound, `{}`)
+	recorder := NewRecording(s.cloudApi())
+
+	sha, err := recorder.UpdateRef(s.ctx, "easy-release--develop", "base", "")
+	s.Require().NoError(err)
+	s.Equal("base", sha)
+	s.Require().Len(recorder.Plan().Actions, 1)
+	s.Equal(ActionUpdateRef, recorder.Plan().Actions[0].Action)
+}
+
+func (s *BitbucketTestSuite) TestCloudPullRequests() {
+	s.stub.handle("GET "+bitbucketCloudRepo+"/pullrequests", http.StatusOK, `{"values":[{"id":3}]}`)
+	s.stub.handle("POST "+bitbucketCloudRepo+"/pullrequests", http.StatusCreated, `{"id":4}`)
+	s.stub.handle("GET "+bitbucketCloudRepo+"/pullrequests/3", http.StatusOK, `{"id":3,"title":"fix: [JIRA-1] a fix"}`)
+	api := s.cloudApi()
+
+	id, err := api.GetPR(s.ctx, "master", "easy-release--master")
+	s.Require().NoError(err)
+	s.Equal(3, id)
+
+	id, err = api.CreatePR(s.ctx, "master", "easy-release--master", "title", "body")
+	s.Require().NoError(err)
+	s.Equal(4, id)
+
+	title, err := api.GetPRTitle(s.ctx, 3)
+	s.Require().NoError(err)
+	s.Equal("fix: [JIRA-1] a fix", title)
+}
+
+func (s *BitbucketTestSuite) TestDataCenterPullRequestSurvivesTheNextRun() {
+	s.stub.handle("GET "+bitbucketDataCenterRepo+"/branches", http.StatusOK, `{"values":[{"displayId":"easy-release--master","latestCommit":"release"}]}`)
+	s.stub.handle("DELETE "+bitbucketBranchUtils+"/branches", http.StatusNoContent, ``)
+	s.stub.handle("POST "+bitbucketBranchUtils+"/branches", http.StatusOK, `{}`)
+	s.stub.handle("PUT "+bitbucketDataCenterRepo+"/browse/CHANGELOG.md", http.StatusOK, `{"id":"next"}`)
+	s.stub.handle("GET "+bitbucketDataCenterRepo+"/pull-requests", http.StatusOK, `{"values":[{"id":5,"toRef":{"id":"refs/heads/master"}}]}`)
+	api := s.dataCenterApi()
+
+	sha, err := api.UpdateRef(s.ctx, "easy-release--master", "base", "release")
+	s.Require().NoError(err)
+	s.Equal("release", sha, "the existing branch is kept")
+
+	s.Require().NoError(api.PushCommit(s.ctx, "easy-release--master", sha, "chore(release): 1.1.0", []RemoteChange{
+		{Path: "CHANGELOG.md", Content: "## 1.1.0"},
+	}))
+	s.Equal("release", s.stub.lastRequest("PUT " + bitbucketDataCenterRepo + "/browse/CHANGELOG.md")["sourceCommitId"])
+
+	id, err := api.GetPR(s.ctx, "master", "easy-release--master")
+	s.Require().NoError(err)
+	s.Equal(5, id)
+
+	s.Zero(s.stub.calls("DELETE "+bitbucketBranchUtils+"/branches"), "deleting the branch declines the pull request")
+	s.Zero(s.stub.calls("POST " + bitbucketBranchUtils + "/branches"))
+}
+
+func (s *BitbucketTestSuite) TestDataCenterPushCommitChainsFiles() {
+	s.stub.handle("PUT "+bitbucketDataCenterRepo+"/browse/CHANGELOG.md", http.StatusOK, `{"id":"first"}`)
+	s.stub.handle("PUT "+bitbucketDataCenterRepo+"/browse/pom.xml", http.StatusOK, `{"id":"second"}`)
+	api := s.dataCenterApi()
+
+	err := api.PushCommit(s.ctx, "easy-release--master", "base", "chore(release): 1.0.0", []RemoteChange{
+		{Path: "CHANGELOG.md", Content: "## 1.0.0"},
+		{Path: "pom.xml", Content: "<project/>"},
+	})
+	s.Require().NoError(err)
+
+	s.Equal("base", s.stub.lastRequest("PUT " + bitbucketDataCenterRepo + "/browse/CHANGELOG.md")["sourceCommitId"])
+	s.Equal("first", s.stub.lastRequest("PUT " + bitbucketDataCenterRepo + "/browse/pom.xml")["sourceCommitId"])
+	s.Equal("chore(release): 1.0.0", s.stub.lastRequest("PUT " + bitbucketDataCenterRepo + "/browse/CHANGELOG.md")["message"])
+	s.Equal("chore(release): 1.0.0", s.stub.lastRequest("PUT " + bitbucketDataCenterRepo + "/browse/pom.xml")["message"])
+}
+
+func (s *BitbucketTestSuite) TestDataCenterReleaseFlow() {
+	s.stub.handle("GET "+bitbucketDataCenterRepo+"/branches", http.StatusOK, `{"values":[{"displayId":"master-old","latestCommit":"x"},{"displayId":"master","latestCommit":"abc"}]}`)
+	s.stub.handle("POST "+bitbucketBranchUtils+"/branches", http.StatusOK, `{}`)
+	s.stub.handle("GET "+bitbucketDataCenterRepo+"/pull-requests/5", http.StatusOK, `{"id":5,"version":2,"title":"old"}`)
+	s.stub.handle("PUT "+bitbucketDataCenterRepo+"/pull-requests/5", http.StatusOK, `{"id":5}`)
+	s.stub.handle("GET "+bitbucketDataCenterRepo+"/commits", http.StatusOK, `{"values":[{"id":"abc","message":"chore(release): 1.0.0"}]}`)
+	s.stub.handle("POST "+bitbucketDataCenterRepo+"/tags", http.StatusOK, `{}`)
+	api := s.dataCenterApi()
+
+	sha, err := api.GetLastRef(s.ctx, "master")
+	s.Require().NoError(err)
+	s.Equal("abc", sha)
+
+	_, err = api.UpdateRef(s.ctx, "easy-release--master", "abc", "0000")
+	s.Require().NoError(err)
+	s.Equal("abc", s.stub.lastRequest("POST " + bitbucketBranchUtils + "/branches")["startPoint"])
+
+	id, err := api.UpdatePR(s.ctx, 5, "new title", "body")
+	s.Require().NoError(err)
+	s.Equal(5, id)
+	s.Equal(float64(2), s.stub.lastRequest("PUT " + bitbucketDataCenterRepo + "/pull-requests/5")["version"])
+
+	sha, message, err := api.GetLastCommitMessage(s.ctx, "master")
+	s.Require().NoError(err)
+	s.Equal("abc", sha)
+	s.Equal("chore(release): 1.0.0", message)
+
+	s.Require().NoError(api.CreateAnnotatedTag(s.ctx, sha, "1.0.0"))
+	s.Equal("1.0.0", s.stub.lastRequest("POST " + bitbucketDataCenterRepo + "/tags")["message"])
+}
+
+func TestBitbucketTestSuite(t *testing.T) {
+	suite.Run(t, new(BitbucketTestSuite))
+}
@@ -0,0 +1,334 @@
+package vcs
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/rikotsev/easy-release/internal/config"
+)
+
+const (
+	giteaDefaultBaseUrl = "https://gitea.com"
+	giteaPageSize       = 50
+)
+
+type giteaApiImpl struct {
+	cfg    *config.Config
+	opts   ApiOpts
+	client *restClient
+}
+
+var _ Api = &giteaApiImpl{}
+var _ RefPlanner = &giteaApiImpl{}
+
+type giteaBranch struct {
+	Name   string `json:"name"`
+	Commit struct {
+		Id string `json:"id"`
+	} `json:"commit"`
+}
+
+type giteaCommit struct {
+	Sha    string `json:"sha"`
+	Commit struct {
+		Message string `json:"message"`
+	} `json:"commit"`
+}
+
+type giteaContent struct {
+	Sha string `json:"sha"`
+}
+
+type giteaFileChange struct {
+	Operation string `json:"operation"`
+	Path      string `json:"path"`
+	Content   string `json:"content"`
+	Sha       string `json:"sha,omitempty"`
+}
+
+type giteaPullRequestBranch struct {
+	Ref string `json:"ref"`
+}
+
+type giteaPullRequest struct {
+	Number int    `json:"number,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Body   string `json:"body,omitempty"`
+}
+
+type giteaPullRequestListItem struct {
+	Number int                    `json:"number"`
+	Title  string                 `json:"title"`
+	Head   giteaPullRequestBranch `json:"head"`
+	Base   giteaPullRequestBranch `json:"base"`
+}
+
+// NewGitea creates an api for a Gitea or Forgejo server located at ApiOpts.BaseUrl (defaults to gitea.com).
+// ApiOpts.Project is the owner (user or organization) of the repository.
+func NewGitea(cfg *config.Config, opts ApiOpts) (Api, error) {
+	baseUrl := opts.BaseUrl
+	if baseUrl == "" {
+		baseUrl = giteaDefaultBaseUrl
+	}
+
+	return &giteaApiImpl{
+		cfg:  cfg,
+		opts: opts,
+		client: newRestClient(baseUrl+"/api/v1", map[string]string{
+			"Authorization": "token " + opts.Token,
+		}),
+	}, nil
+}
+
+func (g *giteaApiImpl) repoPath(format string, args ...interface{}) string {
+	return fmt.Sprintf("/repos/%s/%s", url.PathEscape(g.opts.Project), url.PathEscape(g.opts.Repo)) + fmt.Sprintf(format, args...)
+}
+
+func (g *giteaApiImpl) GetLastRef(ctx context.Context, branch string) (string, error) {
+	var resp giteaBranch
+	status, err := g.client.do(ctx, http.MethodGet, g.repoPath("/branches/%s", url.PathEscape(branch)), nil, &resp)
+
+	if status == http.StatusNotFound {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to get last ref for branch: %s with err: %w", branch, err)
+	}
+
+	return resp.Commit.Id, nil
+}
+
+// UpdateRef creates the branch on newSha when it does not exist. There is no api for force moving an existing branch
+// and deleting it closes its pull request, so an open release pull request is brought up to date by merging the base
+// branch into it and the new last sha is returned. A release branch without an open pull request is re-created on newSha.
+func (g *giteaApiImpl) UpdateRef(ctx context.Context, branch string, newSha string, oldSha string) (string, error) {
+	currentSha, err := g.GetLastRef(ctx, branch)
+	if err != nil {
+		return "", err
+	}
+
+	if currentSha != "" {
+		prId, err := g.openPR(ctx, branch)
+		if err != nil {
+			return "", err
+		}
+
+		if prId != -1 {
+			return g.updatePRWithBase(ctx, branch, prId)
+		}
+
+		_, err = g.client.do(ctx, http.MethodDelete, g.repoPath("/branches/%s", url.PathEscape(branch)), nil, nil)
+		if err != nil {
+			return "", fmt.Errorf("gitea: %w: failed to delete the stale branch: %v", ErrCannotCreateBranch, err)
+		}
+	}
+
+	_, err = g.client.do(ctx, http.MethodPost, g.repoPath("/branches"), map[string]string{
+		"new_branch_name": branch,
+		"old_ref_name":    newSha,
+	}, nil)
+	if err != nil {
+		return "", fmt.Errorf("gitea: %w: %v", ErrCannotCreateBranch, err)
+	}
+
+	return newSha, nil
+}
+
+// PlanRef reports what UpdateRef would do to the branch without changing it.
+func (g *giteaApiImpl) PlanRef(ctx context.Context, branch string, newSha string, oldSha string) (PlannedRef, error) {
+	currentSha, err := g.GetLastRef(ctx, branch)
+	if err != nil {
+		return PlannedRef{}, err
+	}
+
+	if currentSha == "" {
+		return PlannedRef{Action: ActionUpdateRef, Sha: newSha}, nil
+	}
+
+	prId, err := g.openPR(ctx, branch)
+	if err != nil {
+		return PlannedRef{}, err
+	}
+
+	if prId != -1 {
+		return PlannedRef{Action: ActionMergeBase, Sha: currentSha, PrId: prId}, nil
+	}
+
+	return PlannedRef{Action: ActionUpdateRef, Sha: newSha}, nil
+}
+
+func (g *giteaApiImpl) updatePRWithBase(ctx context.Context, branch string, prId int) (string, error) {
+	query := url.Values{}
+	query.Set("style", "merge")
+
+	_, err := g.client.do(ctx, http.MethodPost, g.repoPath("/pulls/%d/update?%s", prId, query.Encode()), nil, nil)
+	if err != nil {
+		return "", fmt.Errorf("gitea: %w: failed to merge the base branch into PR %d, resolve the conflict or close the PR: %v", ErrCannotUpdatePullRequest, prId, err)
+	}
+
+	return g.GetLastRef(ctx, branch)
+}
+
+// PushCommit uses the contents api which requires the blob sha of every file that is being updated. The api always
+// commits on the head of the branch, which UpdateRef has brought up to date with the base branch.
+func (g *giteaApiImpl) PushCommit(ctx context.Context, branch string, lastSha string, message string, changes []RemoteChange) error {
+	files := make([]giteaFileChange, 0, len(changes))
+
+	for _, change := range changes {
+		query := url.Values{}
+		query.Set("ref", branch)
+
+		var content giteaContent
+		status, err := g.client.do(ctx, http.MethodGet, g.repoPath("/contents/%s?%s", change.Path, query.Encode()), nil, &content)
+		if err != nil && status != http.StatusNotFound {
+			return fmt.Errorf("failed to get the current content of: %s with: %w", change.Path, err)
+		}
+
+		fileChange := giteaFileChange{
+			Operation: "create",
+			Path:      change.Path,
+			Content:   base64.StdEncoding.EncodeToString([]byte(change.Content)),
+		}
+		if status != http.StatusNotFound {
+			fileChange.Operation = "update"
+			fileChange.Sha = content.Sha
+		}
+
+		files = append(files, fileChange)
+	}
+
+	_, err := g.client.do(ctx, http.MethodPost, g.repoPath("/contents"), map[string]interface{}{
+		"branch":  branch,
+		"message": message,
+		"files":   files,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to push gitea commit: %w", err)
+	}
+
+	return nil
+}
+
+func (g *giteaApiImpl) GetPR(ctx context.Context, toBranch string, fromBranch string) (int, error) {
+	prId, err := g.findPR(ctx, func(pr giteaPullRequestListItem) bool {
+		return pr.Head.Ref == fromBranch && pr.Base.Ref == toBranch
+	})
+	if err != nil {
+		return -1, fmt.Errorf("failed to retrieve pull requests from branch: %s to branch: %s with err: %w", fromBranch, toBranch, err)
+	}
+
+	return prId, nil
+}
+
+func (g *giteaApiImpl) openPR(ctx context.Context, fromBranch string) (int, error) {
+	prId, err := g.findPR(ctx, func(pr giteaPullRequestListItem) bool {
+		return pr.Head.Ref == fromBranch
+	})
+	if err != nil {
+		return -1, fmt.Errorf("failed to retrieve pull requests from branch: %s with err: %w", fromBranch, err)
+	}
+
+	return prId, nil
+}
+
+// findPR pages through the open pull requests as they cannot be filtered by the head branch.
+func (g *giteaApiImpl) findPR(ctx context.Context, matches func(pr giteaPullRequestListItem) bool) (int, error) {
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("state", "open")
+		query.Set("limit", strconv.Itoa(giteaPageSize))
+		query.Set("page", strconv.Itoa(page))
+
+		var list []giteaPullRequestListItem
+		_, err := g.client.do(ctx, http.MethodGet, g.repoPath("/pulls?%s", query.Encode()), nil, &list)
+		if err != nil {
+			return -1, err
+		}
+
+		for _, pr := range list {
+			if matches(pr) {
+				return pr.Number, nil
+			}
+		}
+
+		if len(list) < giteaPageSize {
+			return -1, nil
+		}
+	}
+}
+
+func (g *giteaApiImpl) CreatePR(ctx context.Context, toBranch string, fromBranch string, title string, description string) (int, error) {
+	var resp giteaPullRequest
+	_, err := g.client.do(ctx, http.MethodPost, g.repoPath("/pulls"), map[string]string{
+		"head":  fromBranch,
+		"base":  toBranch,
+		"title": title,
+		"body":  description,
+	}, &resp)
+	if err != nil {
+		return -1, fmt.Errorf("gitea: %w: %v", ErrCannotCreatePullRequest, err)
+	}
+
+	return resp.Number, nil
+}
+
+func (g *giteaApiImpl) UpdatePR(ctx context.Context, prId int, title string, description string) (int, error) {
+	var resp giteaPullRequest
+	_, err := g.client.do(ctx, http.MethodPatch, g.repoPath("/pulls/%d", prId), giteaPullRequest{
+		Title: title,
+		Body:  description,
+	}, &resp)
+	if err != nil {
+		return -1, fmt.Errorf("gitea: %w: %v", ErrCannotUpdatePullRequest, err)
+	}
+
+	return resp.Number, nil
+}
+
+func (g *giteaApiImpl) GetLastCommitMessage(ctx context.Context, branch string) (string, string, error) {
+	query := url.Values{}
+	query.Set("sha", branch)
+	query.Set("limit", "1")
+	query.Set("stat", "false")
+
+	var list []giteaCommit
+	_, err := g.client.do(ctx, http.MethodGet, g.repoPath("/commits?%s", query.Encode()), nil, &list)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get last commit msg: %w", err)
+	}
+
+	if len(list) == 0 {
+		return "", "", nil
+	}
+
+	return list[0].Sha, list[0].Commit.Message, nil
+}
+
+func (g *giteaApiImpl) CreateAnnotatedTag(ctx context.Context, sha string, version string) error {
+	// providing a message is what makes the tag annotated
+	_, err := g.client.do(ctx, http.MethodPost, g.repoPath("/tags"), map[string]string{
+		"tag_name": version,
+		"target":   sha,
+		"message":  version,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("cannot create tag %v in gitea: %w", version, err)
+	}
+
+	return nil
+}
+
+func (g *giteaApiImpl) GetPRTitle(ctx context.Context, prId int) (string, error) {
+	var resp giteaPullRequest
+	_, err := g.client.do(ctx, http.MethodGet, g.repoPath("/pulls/%d", prId), nil, &resp)
+	if err != nil {
+		return "", fmt.Errorf("could not get PR with id: %d with error: %w", prId, err)
+	}
+
+	return resp.Title, nil
+}
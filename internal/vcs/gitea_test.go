@@ -0,0 +1,225 @@
+package vcs
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/rikotsev/easy-release/internal/config"
+	"github.com/stretchr/testify/suite"
+)
+
+const giteaRepo = "/api/v1/repos/owner/repo"
+
+type GiteaTestSuite struct {
+	suite.Suite
+	ctx  context.Context
+	stub *apiStub
+	api  Api
+}
+
+func (s *GiteaTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.stub = newApiStub()
+
+	api, err := NewGitea(config.Default(), ApiOpts{
+		Token:   "secret",
+		Project: "owner",
+		Repo:    "repo",
+		BaseUrl: s.stub.server.URL,
+	})
+	s.Require().NoError(err)
+	s.api = api
+}
+
+func (s *GiteaTestSuite) TearDownTest() {
+	s.stub.close()
+}
+
+func (s *GiteaTestSuite) TestReleaseBranch() {
+	s.stub.handle("GET "+giteaRepo+"/branches/master", http.StatusOK, `{"name":"master","commit":{"id":"abc"}}`)
+	s.stub.handle("POST "+giteaRepo+"/branches", http.StatusCreated, `{}`)
+
+	sha, err := s.api.GetLastRef(s.ctx, "master")
+	s.Require().NoError(err)
+	s.Equal("abc", sha)
+	s.Equal("token secret", s.stub.headers["GET "+giteaRepo+"/branches/master"][0].Get("Authorization"))
+
+	sha, err = s.api.UpdateRef(s.ctx, "easy-release--master", "abc", "0000")
+	s.Require().NoError(err)
+	s.Equal("abc", sha)
+
+	body := s.stub.lastRequest("POST " + giteaRepo + "/branches")
+	s.Equal("easy-release--master", body["new_branch_name"])
+	s.Equal("abc", body["old_ref_name"])
+}
+
+func (s *GiteaTestSuite) TestOpenReleasePullRequestIsUpdatedWithBase() {
+	head := "release"
+	s.stub.mux.HandleFunc("GET "+giteaRepo+"/branches/easy-release--master", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name":"easy-release--master","commit":{"id":"` + head + `"}}`))
+	})
+	s.stub.handle("GET "+giteaRepo+"/pulls", http.StatusOK, `[{"number":2,"head":{"ref":"easy-release--master"},"base":{"ref":"master"}}]`)
+	s.stub.mux.HandleFunc("POST "+giteaRepo+"/pulls/2/update", func(w http.ResponseWriter, r *http.Request) {
+		s.Equal("merge", r.URL.Query().Get("style"))
+		head = "merged"
+	})
+	s.stub.handle("DELETE "+giteaRepo+"/branches/easy-release--master", http.StatusNoContent, ``)
+	s.stub.handle("POST "+giteaRepo+"/branches", http.StatusCreated, `{}`)
+
+	sha, err := s.api.UpdateRef(s.ctx, "easy-release--master", "abc", "release")
+	s.Require().NoError(err)
+	s.Equal("merged", sha, "the release commit goes on top of the base branch merged into the release branch")
+
+	s.Zero(s.stub.calls("DELETE "+giteaRepo+"/branches/easy-release--master"), "deleting the branch closes the pull request")
+	s.Zero(s.stub.calls("POST " + giteaRepo + "/branches"))
+}
+
+func (s *GiteaTestSuite) TestConflictingReleasePullRequestFails() {
+	s.stub.handle("GET "+giteaRepo+"/branches/easy-release--master", http.StatusOK, `{"name":"easy-release--master","commit":{"id":"release"}}`)
+	s.stub.handle("GET "+giteaRepo+"/pulls", http.StatusOK, `[{"number":2,"head":{"ref":"easy-release--master"},"base":{"ref":"master"}}]`)
+	s.stub.handle("POST "+giteaRepo+"/pulls/2/update", http.StatusConflict, `{"message":"merge conflict"}`)
+
+	_, err := s.api.UpdateRef(s.ctx, "easy-release--master", "abc", "release")
+	s.ErrorIs(err, ErrCannotUpdatePullRequest)
+}
+
+func (s *GiteaTestSuite) TestStaleReleaseBranchIsRecreated() {
+	s.stub.handle("GET "+giteaRepo+"/branches/easy-release--master", http.StatusOK, `{"name":"easy-release--master","commit":{"id":"release"}}`)
+	s.stub.handle("GET "+giteaRepo+"/pulls", http.StatusOK, `[]`)
+	s.stub.handle("DELETE "+giteaRepo+"/branches/easy-release--master", http.StatusNoContent, ``)
+	s.stub.handle("POST "+giteaRepo+"/branches", http.StatusCreated, `{}`)
+
+	sha, err := s.api.UpdateRef(s.ctx, "easy-release--master", "abc", "release")
+	s.Require().NoError(err)
+	s.Equal("abc", sha)
+
+	s.Equal(1, s.stub.calls("DELETE "+giteaRepo+"/branches/easy-release--master"))
+	s.Equal("abc", s.stub.lastRequest("POST " + giteaRepo + "/branches")["old_ref_name"])
+}
+
+func (s *GiteaTestSuite) TestDryRunPlansTheMergeOfTheBaseBranch() {
+	s.stub.handle("GET "+giteaRepo+"/branches/easy-release--master", http.StatusOK, `{"name":"easy-release--master","commit":{"id":"release"}}`)
+	s.stub.handle("GET "+giteaRepo+"/pulls", http.StatusOK, `[{"number":2,"head":{"ref":"easy-release--master"},"base":{"ref":"master"}}]`)
+	s.stub.handle("POST "+giteaRepo+"/pulls/2/update", http.StatusOK, ``)
+	recorder := NewRecording(s.api)
+
+	sha, err := recorder.UpdateRef(s.ctx, "easy-release--master", "abc", "release")
+	s.Require().NoError(err)
+	s.Equal("release", sha)
+	s.Zero(s.stub.calls("POST " + giteaRepo + "/pulls/2/update"))
+
+	plan := recorder.Plan()
+	s.Require().Len(plan.Actions, 1)
+	s.Equal(PlannedAction{Action: ActionMergeBase, Branch: "easy-release--master", Sha: "abc", OldSha: "release", PrId: 2}, plan.Actions[0])
+
+	var text bytes.Buffer
+	s.Require().NoError(plan.WriteText(&text))
+	s.Contains(text.String(), "1. merge abc into branch easy-release--master at release through PR 2")
+}
+
+func (s *GiteaTestSuite) TestPushCommit() {
+	s.stub.handle("GET "+giteaRepo+"/contents/CHANGELOG.md", http.StatusOK, `{"sha":"blob-sha"}`)
+	s.stub.handle("POST "+giteaRepo+"/contents", http.StatusCreated, `{}`)
+
+	err := s.api.PushCommit(s.ctx, "easy-release--master", "abc", "chore(release): 1.0.0", []RemoteChange{
+		{Path: "CHANGELOG.md", Content: "## 1.0.0"},
+		{Path: "version.txt", Content: "1.0.0"},
+	})
+	s.Require().NoError(err)
+
+	body := s.stub.lastRequest("POST " + giteaRepo + "/contents")
+	s.Equal("easy-release--master", body["branch"])
+	s.Equal("chore(release): 1.0.0", body["message"])
+
+	files := body["files"].([]interface{})
+	s.Require().Len(files, 2)
+	changelog := files[0].(map[string]interface{})
+	s.Equal("update", changelog["operation"])
+	s.Equal("blob-sha", changelog["sha"])
+	s.Equal(base64.StdEncoding.EncodeToString([]byte("## 1.0.0")), changelog["content"])
+	s.Equal("create", files[1].(map[string]interface{})["operation"])
+}
+
+func (s *GiteaTestSuite) TestPullRequests() {
+	s.stub.handle("GET "+giteaRepo+"/pulls", http.StatusOK, `[
+		{"number":1,"head":{"ref":"feature"},"base":{"ref":"master"}},
+		{"number":2,"head":{"ref":"easy-release--master"},"base":{"ref":"master"}}
+	]`)
+	s.stub.handle("POST "+giteaRepo+"/pulls", http.StatusCreated, `{"number":3}`)
+	s.stub.handle("PATCH "+giteaRepo+"/pulls/2", http.StatusCreated, `{"number":2}`)
+	s.stub.handle("GET "+giteaRepo+"/pulls/2", http.StatusOK, `{"number":2,"title":"chore(release): 1.0.0"}`)
+
+	id, err := s.api.GetPR(s.ctx, "master", "easy-release--master")
+	s.Require().NoError(err)
+	s.Equal(2, id)
+
+	id, err = s.api.GetPR(s.ctx, "develop", "easy-release--develop")
+	s.Require().NoError(err)
+	s.Equal(-1, id)
+
+	id, err = s.api.CreatePR(s.ctx, "master", "easy-release--master", "title", "body")
+	s.Require().NoError(err)
+	s.Equal(3, id)
+
+	id, err = s.api.UpdatePR(s.ctx, 2, "title", "body")
+	s.Require().NoError(err)
+	s.Equal(2, id)
+
+	title, err := s.api.GetPRTitle(s.ctx, 2)
+	s.Require().NoError(err)
+	s.Equal("chore(release): 1.0.0", title)
+}
+
+func (s *GiteaTestSuite) TestPullRequestsArePaged() {
+	pages := []string{}
+	s.stub.mux.HandleFunc("GET "+giteaRepo+"/pulls", func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+
+		list := []string{}
+		switch page {
+		case "1":
+			for idx := 0; idx < giteaPageSize; idx++ {
+				list = append(list, fmt.Sprintf(`{"number":%d,"head":{"ref":"feature-%d"},"base":{"ref":"master"}}`, idx+1, idx))
+			}
+		case "2":
+			list = append(list, `{"number":51,"head":{"ref":"easy-release--master"},"base":{"ref":"master"}}`)
+		}
+
+		_, _ = w.Write([]byte("[" + strings.Join(list, ",") + "]"))
+	})
+
+	id, err := s.api.GetPR(s.ctx, "master", "easy-release--master")
+	s.Require().NoError(err)
+	s.Equal(51, id)
+
+	id, err = s.api.GetPR(s.ctx, "develop", "easy-release--develop")
+	s.Require().NoError(err)
+	s.Equal(-1, id)
+	s.Equal([]string{"1", "2", "1", "2"}, pages)
+}
+
+func (s *GiteaTestSuite) TestTagging() {
+	s.stub.handle("GET "+giteaRepo+"/commits", http.StatusOK, `[{"sha":"abc","commit":{"message":"chore(release): 1.0.0"}}]`)
+	s.stub.handle("POST "+giteaRepo+"/tags", http.StatusCreated, `{}`)
+
+	sha, message, err := s.api.GetLastCommitMessage(s.ctx, "master")
+	s.Require().NoError(err)
+	s.Equal("abc", sha)
+	s.Equal("chore(release): 1.0.0", message)
+
+	s.Require().NoError(s.api.CreateAnnotatedTag(s.ctx, sha, "1.0.0"))
+	body := s.stub.lastRequest("POST " + giteaRepo + "/tags")
+	s.Equal("1.0.0", body["tag_name"])
+	s.Equal("abc", body["target"])
+	s.Equal("1.0.0", body["message"])
+}
+
+func TestGiteaTestSuite(t *testing.T) {
+	suite.Run(t, new(GiteaTestSuite))
+}
@@ -3,8 +3,10 @@ package vcs
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"time"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/google/go-github/v75/github"
 	"github.com/rikotsev/easy-release/internal/config"
 	"github.com/rikotsev/easy-release/internal/util"
@@ -17,6 +19,9 @@ type githubApiImpl struct {
 }
 
 var _ Api = &githubApiImpl{}
+var _ ReleasePublisher = &githubApiImpl{}
+var _ LabelReader = &githubApiImpl{}
+var _ LinkProvider = &githubApiImpl{}
 
 func NewGithub(cfg *config.Config, opts ApiOpts) (Api, error) {
 	client := github.NewClient(nil).WithAuthToken(opts.Token)
@@ -203,3 +208,56 @@ func (g *githubApiImpl) GetPRTitle(ctx context.Context, prId int) (string, error
 
 	return pullRequest.GetTitle(), nil
 }
+
+func (g *githubApiImpl) GetPRLabels(ctx context.Context, prId int) ([]string, error) {
+	labels, _, err := g.client.Issues.ListLabelsByIssue(ctx, g.opts.Project, g.opts.Repo, prId, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not get labels of PR with id: %d with error: %w", prId, err)
+	}
+
+	result := make([]string, 0, len(labels))
+	for _, label := range labels {
+		result = append(result, label.GetName())
+	}
+
+	return result, nil
+}
+
+func (g *githubApiImpl) CreateRelease(ctx context.Context, tag string, version string, notes string) error {
+	isPrerelease := g.cfg.Release.Prerelease
+	if sv, err := semver.NewVersion(version); err == nil && sv.Prerelease() != "" {
+		isPrerelease = true
+	}
+
+	release := &github.RepositoryRelease{
+		TagName:    util.Ptr(tag),
+		Name:       util.Ptr(tag),
+		Body:       util.Ptr(notes),
+		Draft:      util.Bool(g.cfg.Release.Draft),
+		Prerelease: util.Bool(isPrerelease),
+	}
+	if g.cfg.Release.MakeLatest != "" {
+		release.MakeLatest = util.Ptr(g.cfg.Release.MakeLatest)
+	}
+
+	_, _, err := g.client.Repositories.CreateRelease(ctx, g.opts.Project, g.opts.Repo, release)
+	if err != nil {
+		return fmt.Errorf("cannot create release %v in github: %w", tag, err)
+	}
+
+	return nil
+}
+
+func (g *githubApiImpl) WebLinks() WebLinks {
+	return GithubWebLinks(g.opts.Project, g.opts.Repo)
+}
+
+// GithubWebLinks are the links of a GitHub repository. They need no api call, so they are known without a token.
+func GithubWebLinks(owner string, repo string) WebLinks {
+	repoUrl := fmt.Sprintf("https://github.com/%s/%s", url.PathEscape(owner), url.PathEscape(repo))
+
+	return WebLinks{
+		Compare: repoUrl + "/compare/{from}...{to}",
+		Commit:  repoUrl + "/commit/{sha}",
+	}
+}
@@ -0,0 +1,210 @@
+package vcs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/rikotsev/easy-release/internal/config"
+)
+
+const gitlabDefaultBaseUrl = "https://gitlab.com"
+
+type gitlabApiImpl struct {
+	cfg       *config.Config
+	opts      ApiOpts
+	client    *restClient
+	projectId string
+}
+
+var _ Api = &gitlabApiImpl{}
+
+type gitlabCommit struct {
+	Id      string `json:"id"`
+	Message string `json:"message"`
+}
+
+type gitlabBranch struct {
+	Name   string       `json:"name"`
+	Commit gitlabCommit `json:"commit"`
+}
+
+type gitlabCommitAction struct {
+	Action   string `json:"action"`
+	FilePath string `json:"file_path"`
+	Content  string `json:"content"`
+}
+
+type gitlabMergeRequest struct {
+	Iid          int    `json:"iid,omitempty"`
+	Title        string `json:"title,omitempty"`
+	Description  string `json:"description,omitempty"`
+	SourceBranch string `json:"source_branch,omitempty"`
+	TargetBranch string `json:"target_branch,omitempty"`
+}
+
+// NewGitlab creates an api for gitlab.com or a self-managed instance when ApiOpts.BaseUrl is set.
+// The project is addressed by its full path - ApiOpts.Project is the namespace (group) and ApiOpts.Repo the project name.
+func NewGitlab(cfg *config.Config, opts ApiOpts) (Api, error) {
+	baseUrl := opts.BaseUrl
+	if baseUrl == "" {
+		baseUrl = gitlabDefaultBaseUrl
+	}
+
+	return &gitlabApiImpl{
+		cfg:  cfg,
+		opts: opts,
+		client: newRestClient(baseUrl+"/api/v4", map[string]string{
+			"PRIVATE-TOKEN": opts.Token,
+		}),
+		projectId: url.PathEscape(fmt.Sprintf("%s/%s", opts.Project, opts.Repo)),
+	}, nil
+}
+
+func (g *gitlabApiImpl) projectPath(format string, args ...interface{}) string {
+	return fmt.Sprintf("/projects/%s", g.projectId) + fmt.Sprintf(format, args...)
+}
+
+func (g *gitlabApiImpl) GetLastRef(ctx context.Context, branch string) (string, error) {
+	var resp gitlabBranch
+	status, err := g.client.do(ctx, http.MethodGet, g.projectPath("/repository/branches/%s", url.PathEscape(branch)), nil, &resp)
+
+	if status == http.StatusNotFound {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to get last ref for branch: %s with err: %w", branch, err)
+	}
+
+	return resp.Commit.Id, nil
+}
+
+// UpdateRef creates the branch if it is missing. GitLab has no api for force moving a branch,
+// so an existing branch is reset by PushCommit, which starts the commit from lastSha and forces it on the branch.
+func (g *gitlabApiImpl) UpdateRef(ctx context.Context, branch string, newSha string, oldSha string) (string, error) {
+	currentSha, err := g.GetLastRef(ctx, branch)
+	if err != nil {
+		return "", err
+	}
+
+	if currentSha == "" {
+		query := url.Values{}
+		query.Set("branch", branch)
+		query.Set("ref", newSha)
+
+		_, err = g.client.do(ctx, http.MethodPost, g.projectPath("/repository/branches?%s", query.Encode()), nil, nil)
+		if err != nil {
+			return "", fmt.Errorf("gitlab: %w: %v", ErrCannotCreateBranch, err)
+		}
+	}
+
+	return newSha, nil
+}
+
+func (g *gitlabApiImpl) PushCommit(ctx context.Context, branch string, lastSha string, message string, changes []RemoteChange) error {
+	actions := make([]gitlabCommitAction, 0, len(changes))
+
+	for _, change := range changes {
+		actions = append(actions, gitlabCommitAction{
+			Action:   "update",
+			FilePath: change.Path,
+			Content:  change.Content,
+		})
+	}
+
+	_, err := g.client.do(ctx, http.MethodPost, g.projectPath("/repository/commits"), map[string]interface{}{
+		"branch":         branch,
+		"commit_message": message,
+		"start_sha":      lastSha,
+		"force":          true,
+		"actions":        actions,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to push gitlab commit: %w", err)
+	}
+
+	return nil
+}
+
+func (g *gitlabApiImpl) GetPR(ctx context.Context, toBranch string, fromBranch string) (int, error) {
+	query := url.Values{}
+	query.Set("state", "opened")
+	query.Set("source_branch", fromBranch)
+	query.Set("target_branch", toBranch)
+
+	var list []gitlabMergeRequest
+	_, err := g.client.do(ctx, http.MethodGet, g.projectPath("/merge_requests?%s", query.Encode()), nil, &list)
+	if err != nil {
+		return -1, fmt.Errorf("failed to retrieve merge requests from branch: %s to branch: %s with err: %w", fromBranch, toBranch, err)
+	}
+
+	if len(list) == 0 {
+		return -1, nil
+	}
+
+	return list[0].Iid, nil
+}
+
+func (g *gitlabApiImpl) CreatePR(ctx context.Context, toBranch string, fromBranch string, title string, description string) (int, error) {
+	var resp gitlabMergeRequest
+	_, err := g.client.do(ctx, http.MethodPost, g.projectPath("/merge_requests"), gitlabMergeRequest{
+		Title:        title,
+		Description:  description,
+		SourceBranch: fromBranch,
+		TargetBranch: toBranch,
+	}, &resp)
+	if err != nil {
+		return -1, fmt.Errorf("gitlab: %w: %v", ErrCannotCreatePullRequest, err)
+	}
+
+	return resp.Iid, nil
+}
+
+func (g *gitlabApiImpl) UpdatePR(ctx context.Context, prId int, title string, description string) (int, error) {
+	var resp gitlabMergeRequest
+	_, err := g.client.do(ctx, http.MethodPut, g.projectPath("/merge_requests/%d", prId), gitlabMergeRequest{
+		Title:       title,
+		Description: description,
+	}, &resp)
+	if err != nil {
+		return -1, fmt.Errorf("gitlab: %w: %v", ErrCannotUpdatePullRequest, err)
+	}
+
+	return resp.Iid, nil
+}
+
+func (g *gitlabApiImpl) GetLastCommitMessage(ctx context.Context, branch string) (string, string, error) {
+	var resp gitlabCommit
+	_, err := g.client.do(ctx, http.MethodGet, g.projectPath("/repository/commits/%s", url.PathEscape(branch)), nil, &resp)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get last commit msg: %w", err)
+	}
+
+	return resp.Id, resp.Message, nil
+}
+
+func (g *gitlabApiImpl) CreateAnnotatedTag(ctx context.Context, sha string, version string) error {
+	// providing a message is what makes the tag annotated in gitlab
+	_, err := g.client.do(ctx, http.MethodPost, g.projectPath("/repository/tags"), map[string]string{
+		"tag_name": version,
+		"ref":      sha,
+		"message":  version,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("cannot create tag %v in gitlab: %w", version, err)
+	}
+
+	return nil
+}
+
+func (g *gitlabApiImpl) GetPRTitle(ctx context.Context, prId int) (string, error) {
+	var resp gitlabMergeRequest
+	_, err := g.client.do(ctx, http.MethodGet, g.projectPath("/merge_requests/%d", prId), nil, &resp)
+	if err != nil {
+		return "", fmt.Errorf("could not get MR with id: %d with error: %w", prId, err)
+	}
+
+	return resp.Title, nil
+}
@@ -0,0 +1,117 @@
+package vcs
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/rikotsev/easy-release/internal/config"
+	"github.com/stretchr/testify/suite"
+)
+
+const gitlabProject = "/api/v4/projects/group%2Fproject"
+
+type GitlabTestSuite struct {
+	suite.Suite
+	ctx  context.Context
+	stub *apiStub
+	api  Api
+}
+
+func (s *GitlabTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.stub = newApiStub()
+
+	api, err := NewGitlab(config.Default(), ApiOpts{
+		Token:   "secret",
+		Project: "group",
+		Repo:    "project",
+		BaseUrl: s.stub.server.URL,
+	})
+	s.Require().NoError(err)
+	s.api = api
+}
+
+func (s *GitlabTestSuite) TearDownTest() {
+	s.stub.close()
+}
+
+func (s *GitlabTestSuite) TestGetLastRef() {
+	s.stub.handle("GET "+gitlabProject+"/repository/branches/master", http.StatusOK, `{"name":"master","commit":{"id":"abc"}}`)
+
+	sha, err := s.api.GetLastRef(s.ctx, "master")
+	s.Require().NoError(err)
+	s.Equal("abc", sha)
+	s.Equal("secret", s.stub.headers["GET "+gitlabProject+"/repository/branches/master"][0].Get("PRIVATE-TOKEN"))
+
+	sha, err = s.api.GetLastRef(s.ctx, "missing")
+	s.Require().NoError(err)
+	s.Equal("", sha)
+}
+
+func (s *GitlabTestSuite) TestUpdateRefCreatesMissingBranch() {
+	s.stub.handle("POST "+gitlabProject+"/repository/branches", http.StatusCreated, `{"name":"easy-release--master"}`)
+
+	sha, err := s.api.UpdateRef(s.ctx, "easy-release--master", "abc", "0000")
+	s.Require().NoError(err)
+	s.Equal("abc", sha)
+	s.Equal(1, s.stub.calls("POST "+gitlabProject+"/repository/branches"))
+}
+
+func (s *GitlabTestSuite) TestPushCommit() {
+	s.stub.handle("POST "+gitlabProject+"/repository/commits", http.StatusCreated, `{"id":"def"}`)
+
+	err := s.api.PushCommit(s.ctx, "easy-release--master", "abc", "chore(release): 1.0.0", []RemoteChange{
+		{Path: "CHANGELOG.md", Content: "## 1.0.0"},
+		{Path: "pom.xml", Content: "<project/>"},
+	})
+	s.Require().NoError(err)
+
+	body := s.stub.lastRequest("POST " + gitlabProject + "/repository/commits")
+	s.Equal("easy-release--master", body["branch"])
+	s.Equal("abc", body["start_sha"])
+	s.Equal(true, body["force"])
+	s.Len(body["actions"], 2)
+}
+
+func (s *GitlabTestSuite) TestMergeRequests() {
+	s.stub.handle("GET "+gitlabProject+"/merge_requests", http.StatusOK, `[{"iid":7,"title":"chore(release): 1.0.0"}]`)
+	s.stub.handle("POST "+gitlabProject+"/merge_requests", http.StatusCreated, `{"iid":8}`)
+	s.stub.handle("PUT "+gitlabProject+"/merge_requests/7", http.StatusOK, `{"iid":7}`)
+	s.stub.handle("GET "+gitlabProject+"/merge_requests/7", http.StatusOK, `{"iid":7,"title":"feat: [JIRA-1] something"}`)
+
+	id, err := s.api.GetPR(s.ctx, "master", "easy-release--master")
+	s.Require().NoError(err)
+	s.Equal(7, id)
+
+	id, err = s.api.CreatePR(s.ctx, "master", "easy-release--master", "title", "description")
+	s.Require().NoError(err)
+	s.Equal(8, id)
+
+	id, err = s.api.UpdatePR(s.ctx, 7, "title", "description")
+	s.Require().NoError(err)
+	s.Equal(7, id)
+
+	title, err := s.api.GetPRTitle(s.ctx, 7)
+	s.Require().NoError(err)
+	s.Equal("feat: [JIRA-1] something", title)
+}
+
+func (s *GitlabTestSuite) TestTagging() {
+	s.stub.handle("GET "+gitlabProject+"/repository/commits/master", http.StatusOK, `{"id":"abc","message":"chore(release): 1.0.0"}`)
+	s.stub.handle("POST "+gitlabProject+"/repository/tags", http.StatusCreated, `{"name":"1.0.0"}`)
+
+	sha, message, err := s.api.GetLastCommitMessage(s.ctx, "master")
+	s.Require().NoError(err)
+	s.Equal("abc", sha)
+	s.Equal("chore(release): 1.0.0", message)
+
+	s.Require().NoError(s.api.CreateAnnotatedTag(s.ctx, sha, "1.0.0"))
+	body := s.stub.lastRequest("POST " + gitlabProject + "/repository/tags")
+	s.Equal("1.0.0", body["tag_name"])
+	s.Equal("1.0.0", body["message"])
+}
+
+func TestGitlabTestSuite(t *testing.T) {
+	suite.Run(t, new(GitlabTestSuite))
+}
@@ -0,0 +1,206 @@
+package vcs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rikotsev/easy-release/internal/config"
+)
+
+const (
+	localDefaultRemote = "origin"
+	// LocalDescriptionFile is where the local api writes the title and description of the release "PR".
+	LocalDescriptionFile = ".easy-release-pr.md"
+	localPullRequestId   = 1
+)
+
+// localApiImpl works with nothing more than a git remote with push rights.
+// Branches, commits and tags are created with the git binary in the current working directory and pushed to the remote.
+// As there are no pull requests, the release branch is the "PR" and its description is written to LocalDescriptionFile.
+type localApiImpl struct {
+	cfg    *config.Config
+	opts   ApiOpts
+	remote string
+}
+
+var _ Api = &localApiImpl{}
+
+// NewLocal creates an api backed by the git binary. ApiOpts.BaseUrl is the remote name or url (defaults to origin).
+func NewLocal(cfg *config.Config, opts ApiOpts) (Api, error) {
+	remote := opts.BaseUrl
+	if remote == "" {
+		remote = localDefaultRemote
+	}
+
+	return &localApiImpl{
+		cfg:    cfg,
+		opts:   opts,
+		remote: remote,
+	}, nil
+}
+
+func (l *localApiImpl) git(ctx context.Context, env []string, stdin string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, l.cfg.GitCommand, args...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), env...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("`%s %s` failed with: %w. output was: %s", l.cfg.GitCommand, strings.Join(args, " "), err, stderr.String())
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+func (l *localApiImpl) identity() []string {
+	return []string{
+		"GIT_AUTHOR_NAME=easy-release",
+		"GIT_AUTHOR_EMAIL=no-reply@easy-release.com",
+		"GIT_COMMITTER_NAME=easy-release",
+		"GIT_COMMITTER_EMAIL=no-reply@easy-release.com",
+	}
+}
+
+func (l *localApiImpl) GetLastRef(ctx context.Context, branch string) (string, error) {
+	output, err := l.git(ctx, nil, "", "ls-remote", l.remote, "refs/heads/"+branch)
+	if err != nil {
+		return "", fmt.Errorf("failed to get last ref for branch: %s with err: %w", branch, err)
+	}
+
+	if output == "" {
+		return "", nil
+	}
+
+	return strings.Fields(output)[0], nil
+}
+
+func (l *localApiImpl) UpdateRef(ctx context.Context, branch string, newSha string, oldSha string) (string, error) {
+	if _, err := l.git(ctx, nil, "", "push", "--force", l.remote, fmt.Sprintf("%s:refs/heads/%s", newSha, branch)); err != nil {
+		return "", fmt.Errorf("local: %w: %v", ErrCannotCreateBranch, err)
+	}
+
+	return newSha, nil
+}
+
+// PushCommit builds the commit on top of lastSha with a temporary index, so the working tree is left untouched.
+func (l *localApiImpl) PushCommit(ctx context.Context, branch string, lastSha string, message string, changes []RemoteChange) error {
+	indexDir, err := os.MkdirTemp("", "easy-release-index")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary index: %w", err)
+	}
+	defer os.RemoveAll(indexDir)
+
+	env := append(l.identity(), "GIT_INDEX_FILE="+filepath.Join(indexDir, "index"))
+
+	if _, err := l.git(ctx, env, "", "read-tree", lastSha); err != nil {
+		return fmt.Errorf("failed to read tree of: %s with: %w", lastSha, err)
+	}
+
+	for _, change := range changes {
+		blob, err := l.git(ctx, env, change.Content, "hash-object", "-w", "--stdin")
+		if err != nil {
+			return fmt.Errorf("failed to store content of: %s with: %w", change.Path, err)
+		}
+
+		if _, err := l.git(ctx, env, "", "update-index", "--add", "--cacheinfo", fmt.Sprintf("100644,%s,%s", blob, change.Path)); err != nil {
+			return fmt.Errorf("failed to stage: %s with: %w", change.Path, err)
+		}
+	}
+
+	tree, err := l.git(ctx, env, "", "write-tree")
+	if err != nil {
+		return fmt.Errorf("failed to write tree: %w", err)
+	}
+
+	commit, err := l.git(ctx, env, "", "commit-tree", tree, "-p", lastSha, "-m", message)
+	if err != nil {
+		return fmt.Errorf("failed to create commit: %w", err)
+	}
+
+	if _, err := l.git(ctx, nil, "", "push", "--force", l.remote, fmt.Sprintf("%s:refs/heads/%s", commit, branch)); err != nil {
+		return fmt.Errorf("failed to push commit to: %s with: %w", branch, err)
+	}
+
+	return nil
+}
+
+// GetPR reports the release branch as the only "PR" once a description was generated for it.
+func (l *localApiImpl) GetPR(ctx context.Context, toBranch string, fromBranch string) (int, error) {
+	sha, err := l.GetLastRef(ctx, fromBranch)
+	if err != nil {
+		return -1, err
+	}
+
+	if _, err := os.Stat(LocalDescriptionFile); err != nil || sha == "" {
+		return -1, nil
+	}
+
+	return localPullRequestId, nil
+}
+
+func (l *localApiImpl) CreatePR(ctx context.Context, toBranch string, fromBranch string, title string, description string) (int, error) {
+	if err := l.writeDescription(title, description); err != nil {
+		return -1, fmt.Errorf("local: %w: %v", ErrCannotCreatePullRequest, err)
+	}
+
+	return localPullRequestId, nil
+}
+
+func (l *localApiImpl) UpdatePR(ctx context.Context, prId int, title string, description string) (int, error) {
+	if err := l.writeDescription(title, description); err != nil {
+		return -1, fmt.Errorf("local: %w: %v", ErrCannotUpdatePullRequest, err)
+	}
+
+	return localPullRequestId, nil
+}
+
+func (l *localApiImpl) writeDescription(title string, description string) error {
+	return os.WriteFile(LocalDescriptionFile, []byte(fmt.Sprintf("# %s\n%s", title, description)), 0644)
+}
+
+func (l *localApiImpl) GetLastCommitMessage(ctx context.Context, branch string) (string, string, error) {
+	if _, err := l.git(ctx, nil, "", "fetch", l.remote, "refs/heads/"+branch); err != nil {
+		return "", "", fmt.Errorf("failed to fetch branch: %s with: %w", branch, err)
+	}
+
+	output, err := l.git(ctx, nil, "", "log", "-1", "--format=%H%n%B", "FETCH_HEAD")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get last commit msg: %w", err)
+	}
+
+	sha, message, _ := strings.Cut(output, "\n")
+
+	return sha, strings.TrimSpace(message), nil
+}
+
+func (l *localApiImpl) CreateAnnotatedTag(ctx context.Context, sha string, version string) error {
+	if _, err := l.git(ctx, l.identity(), "", "tag", "-a", version, sha, "-m", version); err != nil {
+		return fmt.Errorf("cannot create tag %v: %w", version, err)
+	}
+
+	if _, err := l.git(ctx, nil, "", "push", l.remote, "refs/tags/"+version); err != nil {
+		return fmt.Errorf("cannot push tag %v: %w", version, err)
+	}
+
+	return nil
+}
+
+// GetPRTitle has no pull request to look at, so the subject of the checked out commit is used instead.
+func (l *localApiImpl) GetPRTitle(ctx context.Context, prId int) (string, error) {
+	output, err := l.git(ctx, nil, "", "log", "-1", "--format=%s", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("could not get the last commit subject with error: %w", err)
+	}
+
+	return output, nil
+}
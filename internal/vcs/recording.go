@@ -0,0 +1,315 @@
+package vcs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	ActionUpdateRef          = "UPDATE_REF"
+	ActionMergeBase          = "MERGE_BASE"
+	ActionPushCommit         = "PUSH_COMMIT"
+	ActionCreatePR           = "CREATE_PR"
+	ActionUpdatePR           = "UPDATE_PR"
+	ActionCreateAnnotatedTag = "CREATE_ANNOTATED_TAG"
+	ActionCreateRelease      = "CREATE_RELEASE"
+	diffContextLines         = 3
+)
+
+// PlannedAction is a write against the vcs that was recorded instead of being performed.
+type PlannedAction struct {
+	Action       string        `json:"action"`
+	Branch       string        `json:"branch,omitempty"`
+	TargetBranch string        `json:"targetBranch,omitempty"`
+	Sha          string        `json:"sha,omitempty"`
+	OldSha       string        `json:"oldSha,omitempty"`
+	Message      string        `json:"message,omitempty"`
+	Files        []PlannedFile `json:"files,omitempty"`
+	PrId         int           `json:"prId,omitempty"`
+	Title        string        `json:"title,omitempty"`
+	Description  string        `json:"description,omitempty"`
+	Version      string        `json:"version,omitempty"`
+}
+
+type PlannedFile struct {
+	Path string `json:"path"`
+	Diff string `json:"diff"`
+}
+
+type Plan struct {
+	Actions []PlannedAction `json:"actions"`
+}
+
+// RecordingApi performs only the read calls against the wrapped api and records every write into a Plan.
+type RecordingApi struct {
+	delegate Api
+	plan     Plan
+}
+
+var _ Api = &RecordingApi{}
+var _ ReleasePublisher = &RecordingApi{}
+var _ LabelReader = &RecordingApi{}
+var _ LinkProvider = &RecordingApi{}
+
+func NewRecording(delegate Api) *RecordingApi {
+	return &RecordingApi{
+		delegate: delegate,
+		plan: Plan{
+			Actions: []PlannedAction{},
+		},
+	}
+}
+
+func (r *RecordingApi) Plan() Plan {
+	return r.plan
+}
+
+func (r *RecordingApi) GetLastRef(ctx context.Context, branch string) (string, error) {
+	return r.delegate.GetLastRef(ctx, branch)
+}
+
+// UpdateRef records a move of the branch to newSha, unless the wrapped api reports that it would do something else.
+func (r *RecordingApi) UpdateRef(ctx context.Context, branch string, newSha string, oldSha string) (string, error) {
+	planned := PlannedRef{Action: ActionUpdateRef, Sha: newSha}
+
+	if planner, ok := r.delegate.(RefPlanner); ok {
+		var err error
+		planned, err = planner.PlanRef(ctx, branch, newSha, oldSha)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	switch planned.Action {
+	case ActionUpdateRef:
+		r.plan.Actions = append(r.plan.Actions, PlannedAction{
+			Action: ActionUpdateRef,
+			Branch: branch,
+			Sha:    planned.Sha,
+			OldSha: oldSha,
+		})
+	case ActionMergeBase:
+		r.plan.Actions = append(r.plan.Actions, PlannedAction{
+			Action: ActionMergeBase,
+			Branch: branch,
+			Sha:    newSha,
+			OldSha: planned.Sha,
+			PrId:   planned.PrId,
+		})
+	}
+
+	return planned.Sha, nil
+}
+
+// PushCommit records a diff of every change against the file in the working directory, which is what the change was computed from.
+func (r *RecordingApi) PushCommit(ctx context.Context, branch string, lastSha string, message string, changes []RemoteChange) error {
+	files := make([]PlannedFile, 0, len(changes))
+
+	for _, change := range changes {
+		current, err := os.ReadFile(change.Path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to read: %s for a diff with: %w", change.Path, err)
+		}
+
+		files = append(files, PlannedFile{
+			Path: change.Path,
+			Diff: diff(change.Path, string(current), change.Content),
+		})
+	}
+
+	r.plan.Actions = append(r.plan.Actions, PlannedAction{
+		Action:  ActionPushCommit,
+		Branch:  branch,
+		Sha:     lastSha,
+		Message: message,
+		Files:   files,
+	})
+
+	return nil
+}
+
+func (r *RecordingApi) GetPR(ctx context.Context, toBranch string, fromBranch string) (int, error) {
+	return r.delegate.GetPR(ctx, toBranch, fromBranch)
+}
+
+func (r *RecordingApi) CreatePR(ctx context.Context, toBranch string, fromBranch string, title string, description string) (int, error) {
+	r.plan.Actions = append(r.plan.Actions, PlannedAction{
+		Action:       ActionCreatePR,
+		Branch:       fromBranch,
+		TargetBranch: toBranch,
+		Title:        title,
+		Description:  description,
+	})
+
+	return -1, nil
+}
+
+func (r *RecordingApi) UpdatePR(ctx context.Context, prId int, title string, description string) (int, error) {
+	r.plan.Actions = append(r.plan.Actions, PlannedAction{
+		Action:      ActionUpdatePR,
+		PrId:        prId,
+		Title:       title,
+		Description: description,
+	})
+
+	return prId, nil
+}
+
+func (r *RecordingApi) GetLastCommitMessage(ctx context.Context, branch string) (string, string, error) {
+	return r.delegate.GetLastCommitMessage(ctx, branch)
+}
+
+func (r *RecordingApi) CreateAnnotatedTag(ctx context.Context, sha string, version string) error {
+	r.plan.Actions = append(r.plan.Actions, PlannedAction{
+		Action:  ActionCreateAnnotatedTag,
+		Sha:     sha,
+		Version: version,
+	})
+
+	return nil
+}
+
+func (r *RecordingApi) GetPRTitle(ctx context.Context, prId int) (string, error) {
+	return r.delegate.GetPRTitle(ctx, prId)
+}
+
+// GetPRLabels is a read call, so it is passed through to the wrapped api when it supports labels.
+func (r *RecordingApi) GetPRLabels(ctx context.Context, prId int) ([]string, error) {
+	reader, ok := r.delegate.(LabelReader)
+	if !ok {
+		return []string{}, nil
+	}
+
+	return reader.GetPRLabels(ctx, prId)
+}
+
+func (r *RecordingApi) WebLinks() WebLinks {
+	provider, ok := r.delegate.(LinkProvider)
+	if !ok {
+		return WebLinks{}
+	}
+
+	return provider.WebLinks()
+}
+
+func (r *RecordingApi) CreateRelease(ctx context.Context, tag string, version string, notes string) error {
+	if _, ok := r.delegate.(ReleasePublisher); !ok {
+		return ErrReleasesNotSupported
+	}
+
+	r.plan.Actions = append(r.plan.Actions, PlannedAction{
+		Action:      ActionCreateRelease,
+		Version:     tag,
+		Description: notes,
+	})
+
+	return nil
+}
+
+func (p Plan) WriteJson(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(p)
+}
+
+func (p Plan) WriteText(w io.Writer) error {
+	var out strings.Builder
+
+	if len(p.Actions) == 0 {
+		out.WriteString("dry run: nothing would have been changed\n")
+	} else {
+		out.WriteString("dry run: the following changes would have been made\n")
+	}
+
+	for idx, action := range p.Actions {
+		out.WriteString(fmt.Sprintf("\n%d. ", idx+1))
+
+		switch action.Action {
+		case ActionUpdateRef:
+			out.WriteString(fmt.Sprintf("update branch %s from %s to %s\n", action.Branch, action.OldSha, action.Sha))
+		case ActionMergeBase:
+			out.WriteString(fmt.Sprintf("merge %s into branch %s at %s through PR %d\n", action.Sha, action.Branch, action.OldSha, action.PrId))
+		case ActionPushCommit:
+			out.WriteString(fmt.Sprintf("commit %q on %s on top of %s\n", action.Message, action.Branch, action.Sha))
+			for _, file := range action.Files {
+				out.WriteString(indent(file.Diff, "   "))
+			}
+		case ActionCreatePR:
+			out.WriteString(fmt.Sprintf("create PR from %s to %s\n   title: %s\n   description:\n", action.Branch, action.TargetBranch, action.Title))
+			out.WriteString(indent(action.Description, "     "))
+		case ActionUpdatePR:
+			out.WriteString(fmt.Sprintf("update PR %d\n   title: %s\n   description:\n", action.PrId, action.Title))
+			out.WriteString(indent(action.Description, "     "))
+		case ActionCreateAnnotatedTag:
+			out.WriteString(fmt.Sprintf("tag %s as %s\n", action.Sha, action.Version))
+		case ActionCreateRelease:
+			out.WriteString(fmt.Sprintf("publish release %s\n   notes:\n", action.Version))
+			out.WriteString(indent(action.Description, "     "))
+		}
+	}
+
+	_, err := io.WriteString(w, out.String())
+
+	return err
+}
+
+func indent(text string, prefix string) string {
+	var out strings.Builder
+
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		out.WriteString(prefix + line + "\n")
+	}
+
+	return out.String()
+}
+
+// diff produces a unified diff with a single hunk spanning from the first to the last changed line.
+// The updates only ever touch a few neighbouring lines, so this is enough to review them.
+func diff(path string, oldContent string, newContent string) string {
+	if oldContent == newContent {
+		return fmt.Sprintf("--- a/%s\n+++ b/%s\n", path, path)
+	}
+
+	oldLines := strings.Split(oldContent, "\n")
+	newLines := strings.Split(newContent, "\n")
+
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	start := max(prefix-diffContextLines, 0)
+	oldEnd := min(len(oldLines)-suffix+diffContextLines, len(oldLines))
+	newEnd := min(len(newLines)-suffix+diffContextLines, len(newLines))
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("--- a/%s\n+++ b/%s\n", path, path))
+	out.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", start+1, oldEnd-start, start+1, newEnd-start))
+
+	for _, line := range oldLines[start:prefix] {
+		out.WriteString(" " + line + "\n")
+	}
+	for _, line := range oldLines[prefix : len(oldLines)-suffix] {
+		out.WriteString("-" + line + "\n")
+	}
+	for _, line := range newLines[prefix : len(newLines)-suffix] {
+		out.WriteString("+" + line + "\n")
+	}
+	for _, line := range oldLines[len(oldLines)-suffix : oldEnd] {
+		out.WriteString(" " + line + "\n")
+	}
+
+	return out.String()
+}
@@ -0,0 +1,124 @@
+package vcs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// readOnlyApi answers the read calls and fails the test on any write.
+type readOnlyApi struct {
+	t *suite.Suite
+}
+
+func (a *readOnlyApi) GetLastRef(ctx context.Context, branch string) (string, error) {
+	return branch + "-sha", nil
+}
+
+func (a *readOnlyApi) UpdateRef(ctx context.Context, branch string, newSha string, oldSha string) (string, error) {
+	a.t.Fail("UpdateRef should not reach the real api")
+	return "", nil
+}
+
+func (a *readOnlyApi) PushCommit(ctx context.Context, branch string, lastSha string, message string, changes []RemoteChange) error {
+	a.t.Fail("PushCommit should not reach the real api")
+	return nil
+}
+
+func (a *readOnlyApi) GetPR(ctx context.Context, toBranch string, fromBranch string) (int, error) {
+	return 42, nil
+}
+
+func (a *readOnlyApi) CreatePR(ctx context.Context, toBranch string, fromBranch string, title string, description string) (int, error) {
+	a.t.Fail("CreatePR should not reach the real api")
+	return -1, nil
+}
+
+func (a *readOnlyApi) UpdatePR(ctx context.Context, prId int, title string, description string) (int, error) {
+	a.t.Fail("UpdatePR should not reach the real api")
+	return -1, nil
+}
+
+func (a *readOnlyApi) GetLastCommitMessage(ctx context.Context, branch string) (string, string, error) {
+	return "abc", "chore(release): 1.0.0", nil
+}
+
+func (a *readOnlyApi) CreateAnnotatedTag(ctx context.Context, sha string, version string) error {
+	a.t.Fail("CreateAnnotatedTag should not reach the real api")
+	return nil
+}
+
+func (a *readOnlyApi) GetPRTitle(ctx context.Context, prId int) (string, error) {
+	return "title", nil
+}
+
+type RecordingTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	recorder *RecordingApi
+}
+
+func (s *RecordingTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.recorder = NewRecording(&readOnlyApi{t: &s.Suite})
+}
+
+func (s *RecordingTestSuite) TestReadsAreDelegated() {
+	sha, err := s.recorder.GetLastRef(s.ctx, "master")
+	s.Require().NoError(err)
+	s.Equal("master-sha", sha)
+
+	id, err := s.recorder.GetPR(s.ctx, "master", "easy-release--master")
+	s.Require().NoError(err)
+	s.Equal(42, id)
+
+	sha, message, err := s.recorder.GetLastCommitMessage(s.ctx, "master")
+	s.Require().NoError(err)
+	s.Equal("abc", sha)
+	s.Equal("chore(release): 1.0.0", message)
+
+	s.Empty(s.recorder.Plan().Actions)
+}
+
+func (s *RecordingTestSuite) TestWritesArePlanned() {
+	path := filepath.Join(s.T().TempDir(), "CHANGELOG.md")
+	s.Require().NoError(os.WriteFile(path, []byte("# Changelog\n\n## 1.0.0\n* first\n"), 0644))
+
+	_, err := s.recorder.UpdateRef(s.ctx, "easy-release--master", "new", "old")
+	s.Require().NoError(err)
+	s.Require().NoError(s.recorder.PushCommit(s.ctx, "easy-release--master", "new", "chore(release): 1.1.0", []RemoteChange{
+		{Path: path, Content: "# Changelog\n\n## 1.1.0\n* second\n\n## 1.0.0\n* first\n"},
+	}))
+	_, err = s.recorder.UpdatePR(s.ctx, 42, "chore(release): 1.1.0", "## 1.1.0")
+	s.Require().NoError(err)
+	s.Require().NoError(s.recorder.CreateAnnotatedTag(s.ctx, "new", "1.1.0"))
+
+	plan := s.recorder.Plan()
+	s.Require().Len(plan.Actions, 4)
+	s.Equal(ActionUpdateRef, plan.Actions[0].Action)
+	s.Equal(ActionPushCommit, plan.Actions[1].Action)
+	s.Equal("--- a/"+path+"\n+++ b/"+path+"\n@@ -1,5 +1,8 @@\n # Changelog\n \n+## 1.1.0\n+* second\n+\n ## 1.0.0\n * first\n \n",
+		plan.Actions[1].Files[0].Diff)
+	s.Equal(ActionUpdatePR, plan.Actions[2].Action)
+	s.Equal(ActionCreateAnnotatedTag, plan.Actions[3].Action)
+
+	var text bytes.Buffer
+	s.Require().NoError(plan.WriteText(&text))
+	s.Contains(text.String(), "1. update branch easy-release--master from old to new")
+	s.Contains(text.String(), "4. tag new as 1.1.0")
+
+	var asJson bytes.Buffer
+	s.Require().NoError(plan.WriteJson(&asJson))
+	var decoded Plan
+	s.Require().NoError(json.Unmarshal(asJson.Bytes(), &decoded))
+	s.Equal(plan, decoded)
+}
+
+func TestRecordingTestSuite(t *testing.T) {
+	suite.Run(t, new(RecordingTestSuite))
+}
@@ -0,0 +1,117 @@
+package vcs
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+var ErrUnexpectedResponse = errors.New("unexpected response from the vcs api")
+
+// restClient is a thin JSON over HTTP client shared by the backends that do not have an SDK in use.
+type restClient struct {
+	baseUrl string
+	headers map[string]string
+	http    *http.Client
+}
+
+func newRestClient(baseUrl string, headers map[string]string) *restClient {
+	return &restClient{
+		baseUrl: strings.TrimSuffix(baseUrl, "/"),
+		headers: headers,
+		http:    http.DefaultClient,
+	}
+}
+
+// do performs a JSON request and decodes the response into out (if not nil).
+// The status code is always returned when a response was received so callers can react to e.g. 404.
+func (c *restClient) do(ctx context.Context, method string, path string, body interface{}, out interface{}) (int, error) {
+	if body == nil {
+		return c.send(ctx, method, path, "", nil, out)
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal request body for %s %s with: %w", method, path, err)
+	}
+
+	return c.send(ctx, method, path, "application/json", bytes.NewReader(payload), out)
+}
+
+// doMultipart performs a multipart/form-data request with the fields sorted by name.
+func (c *restClient) doMultipart(ctx context.Context, method string, path string, fields map[string]string, out interface{}) (int, error) {
+	var payload bytes.Buffer
+	writer := multipart.NewWriter(&payload)
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := writer.WriteField(name, fields[name]); err != nil {
+			return 0, fmt.Errorf("failed to write form field %s for %s %s with: %w", name, method, path, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return 0, fmt.Errorf("failed to close form for %s %s with: %w", method, path, err)
+	}
+
+	return c.send(ctx, method, path, writer.FormDataContentType(), &payload, out)
+}
+
+func (c *restClient) send(ctx context.Context, method string, path string, contentType string, body io.Reader, out interface{}) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseUrl+path, body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request %s %s with: %w", method, path, err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute request %s %s with: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("failed to read response for %s %s with: %w", method, path, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("%w: %s %s returned %d: %s", ErrUnexpectedResponse, method, path, resp.StatusCode, string(respBody))
+	}
+
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to parse response for %s %s with: %w", method, path, err)
+		}
+	}
+
+	return resp.StatusCode, nil
+}
+
+// authorizationHeader uses basic auth for `username:password` tokens and a bearer token otherwise.
+func authorizationHeader(token string) string {
+	if strings.Contains(token, ":") {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(token))
+	}
+
+	return "Bearer " + token
+}
@@ -0,0 +1,76 @@
+package vcs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// apiStub is an httptest stand-in for the rest based backends. It records the decoded body of every handled request.
+type apiStub struct {
+	mux      *http.ServeMux
+	server   *httptest.Server
+	lock     sync.Mutex
+	requests map[string][]map[string]interface{}
+	headers  map[string][]http.Header
+}
+
+func newApiStub() *apiStub {
+	stub := &apiStub{
+		mux:      http.NewServeMux(),
+		requests: map[string][]map[string]interface{}{},
+		headers:  map[string][]http.Header{},
+	}
+	stub.server = httptest.NewServer(stub.mux)
+
+	return stub
+}
+
+func (stub *apiStub) handle(pattern string, status int, response string) {
+	stub.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]interface{}{}
+
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			if err := r.ParseMultipartForm(1 << 20); err == nil {
+				for key, values := range r.MultipartForm.Value {
+					body[key] = values[0]
+				}
+			}
+		} else if r.Body != nil {
+			_ = json.NewDecoder(r.Body).Decode(&body)
+		}
+
+		stub.lock.Lock()
+		stub.requests[pattern] = append(stub.requests[pattern], body)
+		stub.headers[pattern] = append(stub.headers[pattern], r.Header.Clone())
+		stub.lock.Unlock()
+
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	})
+}
+
+func (stub *apiStub) lastRequest(pattern string) map[string]interface{} {
+	stub.lock.Lock()
+	defer stub.lock.Unlock()
+
+	calls := stub.requests[pattern]
+	if len(calls) == 0 {
+		return nil
+	}
+
+	return calls[len(calls)-1]
+}
+
+func (stub *apiStub) calls(pattern string) int {
+	stub.lock.Lock()
+	defer stub.lock.Unlock()
+
+	return len(stub.requests[pattern])
+}
+
+func (stub *apiStub) close() {
+	stub.server.Close()
+}
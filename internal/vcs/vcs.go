@@ -8,6 +8,7 @@ import (
 var ErrCannotCreateBranch = errors.New("cannot create a release branch")
 var ErrCannotCreatePullRequest = errors.New("cannot create PR")
 var ErrCannotUpdatePullRequest = errors.New("cannot update PR")
+var ErrReleasesNotSupported = errors.New("the platform does not support releases")
 
 type Api interface {
 	GetLastRef(ctx context.Context, branch string) (string, error)
@@ -21,6 +22,41 @@ type Api interface {
 	GetPRTitle(ctx context.Context, prId int) (string, error)
 }
 
+// ReleasePublisher is implemented by the platforms that can publish a release with notes for a tag. The version is the
+// tag without its package prefix.
+type ReleasePublisher interface {
+	CreateRelease(ctx context.Context, tag string, version string, notes string) error
+}
+
+// RefPlanner is implemented by the platforms where UpdateRef does not always move the branch to newSha. PlanRef reports
+// what UpdateRef would do without changing anything, so a dry run can show it.
+type RefPlanner interface {
+	PlanRef(ctx context.Context, branch string, newSha string, oldSha string) (PlannedRef, error)
+}
+
+// PlannedRef is the recorded Action (empty when the branch is kept as it is) and the Sha the next commit would go on top of.
+type PlannedRef struct {
+	Action string
+	Sha    string
+	PrId   int
+}
+
+// LabelReader is implemented by the platforms where pull requests carry labels (tags in Azure DevOps).
+type LabelReader interface {
+	GetPRLabels(ctx context.Context, prId int) ([]string, error)
+}
+
+// LinkProvider is implemented by the platforms with a web UI to compare tags and browse commits.
+type LinkProvider interface {
+	WebLinks() WebLinks
+}
+
+// WebLinks are URL patterns - `{from}` and `{to}` are replaced with tags and `{sha}` with a commit sha.
+type WebLinks struct {
+	Compare string
+	Commit  string
+}
+
 const PullRequestDescriptionLimit = 4000
 
 type RemoteChange struct {
@@ -34,4 +70,5 @@ type ApiOpts struct {
 	Project string
 	Repo    string
 	Branch  string
+	BaseUrl string
 }
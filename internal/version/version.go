@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log/slog"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/rikotsev/easy-release/internal/commits"
@@ -12,6 +14,7 @@ import (
 )
 
 var ErrDuplicateType = errors.New("cannot have the same commit type perform different version increments")
+var ErrInvalidReleaseAs = errors.New("invalid Release-As version")
 
 type Manager struct {
 	cfg                 *config.Config
@@ -25,7 +28,24 @@ func New(cfg *config.Config, commitTypeToSection map[string]*config.ChangelogSec
 	}, nil
 }
 
+// TagsWithPrefix returns the tags starting with the prefix with the prefix removed.
+func TagsWithPrefix(tags []string, prefix string) []string {
+	if prefix == "" {
+		return tags
+	}
+
+	result := []string{}
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, prefix) {
+			result = append(result, strings.TrimPrefix(tag, prefix))
+		}
+	}
+
+	return result
+}
+
 // Will determine the last strict semantic version from all tags if any.
+// Prerelease versions are skipped - they only number the next prerelease of the same channel.
 func (m *Manager) Current(versions []string) string {
 	if len(versions) == 0 {
 		return ""
@@ -36,7 +56,7 @@ func (m *Manager) Current(versions []string) string {
 	for _, vers := range versions {
 		sv, err := semver.StrictNewVersion(vers)
 
-		if err != nil {
+		if err != nil || sv.Prerelease() != "" {
 			//TODO log something maybe
 			continue
 		}
@@ -54,7 +74,15 @@ func (m *Manager) Current(versions []string) string {
 	return semVersions[len(semVersions)-1].String()
 }
 
+// Next determines the version after initialSha from the commits. A Release-As footer overrides the computation,
+// the most recent one (the commits are newest first) wins.
 func (m *Manager) Next(initialSha string, parsedCommits []commits.Commit) (string, error) {
+	for _, commit := range parsedCommits {
+		if releaseAs, ok := commit.Footer(commits.ReleaseAsFooter); ok {
+			return m.ReleaseAs(initialSha, releaseAs)
+		}
+	}
+
 	if initialSha == "" {
 		return m.cfg.StartingVersion, nil
 	}
@@ -73,6 +101,11 @@ func (m *Manager) Next(initialSha string, parsedCommits []commits.Commit) (strin
 
 	for _, commit := range parsedCommits {
 
+		if commit.Breaking {
+			increaseMajor = true
+			break
+		}
+
 		section, ok := m.commitTypeToSection[commit.Type]
 		if !ok {
 			// this commit type is not tracked in a section
@@ -108,3 +141,109 @@ func (m *Manager) Next(initialSha string, parsedCommits []commits.Commit) (strin
 
 	return sv.String(), nil
 }
+
+// Releasable reports whether any of the commits is tracked in a section and would increment the version.
+func (m *Manager) Releasable(parsedCommits []commits.Commit) bool {
+	for _, commit := range parsedCommits {
+		if _, ok := m.commitTypeToSection[commit.Type]; ok || commit.Breaking {
+			return true
+		}
+
+		if _, ok := commit.Footer(commits.ReleaseAsFooter); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReleaseAs validates a requested version - it has to be strict semver and greater than the current one.
+func (m *Manager) ReleaseAs(initialSha string, requested string) (string, error) {
+	requestedVersion, err := semver.StrictNewVersion(strings.TrimSpace(requested))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s is not strict semver: %v", ErrInvalidReleaseAs, requested, err)
+	}
+
+	if initialSha == "" {
+		return requestedVersion.String(), nil
+	}
+
+	currentVersion, err := semver.StrictNewVersion(initialSha)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the current version with %w", err)
+	}
+
+	if !requestedVersion.GreaterThan(currentVersion) {
+		return "", fmt.Errorf("%w: %s is not greater than the current version %s", ErrInvalidReleaseAs, requestedVersion, currentVersion)
+	}
+
+	return requestedVersion.String(), nil
+}
+
+// LatestPrerelease determines the last version of the channel (`rc` for `2.0.0-rc.3`) from all tags if any.
+func (m *Manager) LatestPrerelease(versions []string, channel string) string {
+	var latest *semver.Version
+
+	for _, vers := range versions {
+		sv, err := semver.StrictNewVersion(vers)
+		if err != nil {
+			continue
+		}
+
+		if _, ok := prereleaseNumber(sv, channel); !ok {
+			continue
+		}
+
+		if latest == nil || sv.GreaterThan(latest) {
+			latest = sv
+		}
+	}
+
+	if latest == nil {
+		return ""
+	}
+
+	return latest.String()
+}
+
+// NextPrerelease turns the next stable version into the next prerelease of the channel - `2.0.0` into `2.0.0-rc.3`
+// when `2.0.0-rc.2` is the last one among the tags.
+func (m *Manager) NextPrerelease(nextVersion string, channel string, versions []string) (string, error) {
+	next, err := semver.StrictNewVersion(nextVersion)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the next version with %w", err)
+	}
+
+	number := 0
+	for _, vers := range versions {
+		sv, err := semver.StrictNewVersion(vers)
+		if err != nil || sv.Major() != next.Major() || sv.Minor() != next.Minor() || sv.Patch() != next.Patch() {
+			continue
+		}
+
+		if n, ok := prereleaseNumber(sv, channel); ok && n > number {
+			number = n
+		}
+	}
+
+	prerelease, err := next.SetPrerelease(fmt.Sprintf("%s.%d", channel, number+1))
+	if err != nil {
+		return "", fmt.Errorf("prerelease channel: %s is not valid with %w", channel, err)
+	}
+
+	return prerelease.String(), nil
+}
+
+func prereleaseNumber(sv *semver.Version, channel string) (int, bool) {
+	number, found := strings.CutPrefix(sv.Prerelease(), channel+".")
+	if !found {
+		return 0, false
+	}
+
+	result, err := strconv.Atoi(number)
+	if err != nil {
+		return 0, false
+	}
+
+	return result, true
+}
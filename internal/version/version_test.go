@@ -54,6 +54,50 @@ func (suite *VersionTestSuite) TestCurrent() {
 		})
 		suite.Equal("", vers)
 	})
+
+	suite.Run("prereleases are not the current version", func() {
+		vers := suite.manager.Current([]string{
+			"1.2.3",
+			"2.0.0-rc.1",
+			"2.0.0-rc.2",
+		})
+		suite.Equal("1.2.3", vers)
+	})
+}
+
+func (suite *VersionTestSuite) TestPrerelease() {
+	tags := []string{"1.2.3", "2.0.0-beta.1", "2.0.0-rc.1", "2.0.0-rc.2", "2.0.0-rc.10", "1.3.0-rc.4", "2.0.0-rc.x"}
+
+	suite.Run("the latest prerelease of the channel", func() {
+		suite.Equal("2.0.0-rc.10", suite.manager.LatestPrerelease(tags, "rc"))
+		suite.Equal("2.0.0-beta.1", suite.manager.LatestPrerelease(tags, "beta"))
+		suite.Equal("", suite.manager.LatestPrerelease(tags, "alpha"))
+	})
+
+	suite.Run("the next prerelease continues the numbering of the same version", func() {
+		next, err := suite.manager.NextPrerelease("2.0.0", "rc", tags)
+
+		suite.NoError(err)
+		suite.Equal("2.0.0-rc.11", next)
+	})
+
+	suite.Run("the next prerelease of a new version starts from 1", func() {
+		next, err := suite.manager.NextPrerelease("1.4.0", "rc", tags)
+
+		suite.NoError(err)
+		suite.Equal("1.4.0-rc.1", next)
+	})
+
+	suite.Run("the channel has to be a valid prerelease identifier", func() {
+		_, err := suite.manager.NextPrerelease("1.4.0", "r_c", tags)
+
+		suite.Error(err)
+	})
+
+	suite.Run("only tracked commits are releasable", func() {
+		suite.False(suite.manager.Releasable([]commits.Commit{{Type: "chore", Title: "2.0.0-rc.1"}}))
+		suite.True(suite.manager.Releasable([]commits.Commit{{Type: "chore"}, {Type: "fix", Title: "a bug"}}))
+	})
 }
 
 func (suite *VersionTestSuite) TestNext() {
@@ -172,6 +216,63 @@ func (suite *VersionTestSuite) TestNext() {
 		suite.Equal("1.0.0", newVersion)
 	})
 
+	suite.Run("a breaking change footer increases the major version", func() {
+		newVersion, err := suite.manager.Next("1.0.0", []commits.Commit{
+			{
+				Type:  "fix",
+				Title: "a bug",
+			},
+			{
+				Type:     "chore",
+				Title:    "dropped java 11",
+				Breaking: true,
+			},
+		})
+
+		suite.NoError(err)
+		suite.Equal("2.0.0", newVersion)
+	})
+
+	suite.Run("a Release-As footer overrides the computed version", func() {
+		newVersion, err := suite.manager.Next("1.0.0", []commits.Commit{
+			{
+				Type:    "chore",
+				Title:   "the next major",
+				Footers: []commits.Footer{{Token: "release-as", Value: "3.0.0"}},
+			},
+			{
+				Type:    "feat",
+				Title:   "an older request",
+				Footers: []commits.Footer{{Token: "Release-As", Value: "2.0.0"}},
+			},
+		})
+
+		suite.NoError(err)
+		suite.Equal("3.0.0", newVersion)
+	})
+
+	suite.Run("a Release-As version has to be greater than the current one", func() {
+		_, err := suite.manager.Next("1.0.0", []commits.Commit{
+			{
+				Type:    "chore",
+				Footers: []commits.Footer{{Token: "Release-As", Value: "0.9.0"}},
+			},
+		})
+
+		suite.ErrorIs(err, ErrInvalidReleaseAs)
+	})
+
+	suite.Run("a Release-As version has to be strict semver", func() {
+		_, err := suite.manager.Next("", []commits.Commit{
+			{
+				Type:    "chore",
+				Footers: []commits.Footer{{Token: "Release-As", Value: "v3"}},
+			},
+		})
+
+		suite.ErrorIs(err, ErrInvalidReleaseAs)
+	})
+
 	suite.Run("if there is no current version - next should be the default one", func() {
 		newVersion, err := suite.manager.Next("", []commits.Commit{})
 
@@ -181,6 +282,42 @@ func (suite *VersionTestSuite) TestNext() {
 
 }
 
+func (suite *VersionTestSuite) TestHiddenSectionsIncrementTheVersion() {
+	cfg := config.Default()
+	cfg.ChangelogSections = append(cfg.ChangelogSections, config.ChangelogSection{
+		Section:   "Performance",
+		Hidden:    true,
+		Increment: config.IncrementVersionMinor,
+		Includes:  []string{"perf"},
+	})
+	commitTypeToSection, err := config.PivotSections(cfg)
+	suite.Require().NoError(err)
+	manager, err := New(cfg, commitTypeToSection)
+	suite.Require().NoError(err)
+
+	newVersion, err := manager.Next("1.0.0", []commits.Commit{{Type: "perf", Title: "faster startup"}})
+
+	suite.NoError(err)
+	suite.Equal("1.1.0", newVersion)
+}
+
+func (suite *VersionTestSuite) TestTagsWithPrefix() {
+	tags := []string{"1.0.0", "api-1.2.0", "api-1.3.0", "web-0.1.0"}
+
+	suite.Run("without a prefix all tags are kept", func() {
+		suite.Equal(tags, TagsWithPrefix(tags, ""))
+	})
+
+	suite.Run("only tags with the prefix are kept and the prefix is stripped", func() {
+		suite.Equal([]string{"1.2.0", "1.3.0"}, TagsWithPrefix(tags, "api-"))
+		suite.Equal("1.3.0", suite.manager.Current(TagsWithPrefix(tags, "api-")))
+	})
+
+	suite.Run("no tags with the prefix", func() {
+		suite.Empty(TagsWithPrefix(tags, "cli-"))
+	})
+}
+
 func TestVersionTestSuite(t *testing.T) {
 	suite.Run(t, new(VersionTestSuite))
 }